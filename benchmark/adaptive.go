@@ -0,0 +1,153 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Th4phat/go-wrk/config"
+	"github.com/Th4phat/go-wrk/metrics"
+)
+
+// concurrencyGate parks the workers whose ID is at or above its limit.
+type concurrencyGate struct {
+	mu    sync.Mutex
+	cond  *sync.Cond
+	limit int
+}
+
+func newConcurrencyGate(limit int) *concurrencyGate {
+	g := &concurrencyGate{limit: limit}
+	g.cond = sync.NewCond(&g.mu)
+	return g
+}
+
+func (g *concurrencyGate) set(limit int) {
+	g.mu.Lock()
+	g.limit = limit
+	g.mu.Unlock()
+	g.cond.Broadcast()
+}
+
+func (g *concurrencyGate) get() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.limit
+}
+
+// release wakes every parked worker so it can see that ctx is done.
+func (g *concurrencyGate) release() {
+	// Taking the lock orders the broadcast after any check of ctx in wait.
+	g.mu.Lock()
+	g.mu.Unlock()
+	g.cond.Broadcast()
+}
+
+// wait blocks while the worker is parked. It returns false once ctx is done;
+// release must be called when that happens.
+func (g *concurrencyGate) wait(ctx context.Context, workerID int) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for workerID >= g.limit && ctx.Err() == nil {
+		g.cond.Wait()
+	}
+	return ctx.Err() == nil
+}
+
+// concurrencyController decides the number of active workers from the
+// latencies and errors of each interval.
+type concurrencyController struct {
+	p            config.AdaptiveParams
+	limit        int
+	window       []time.Duration
+	windowErrors int
+	prevErr      [2]float64 // Relative errors of the last two decisions, for PID
+}
+
+func newConcurrencyController(p config.AdaptiveParams) *concurrencyController {
+	return &concurrencyController{p: p, limit: p.Initial}
+}
+
+func (c *concurrencyController) observe(latency time.Duration) {
+	c.window = append(c.window, latency)
+}
+
+func (c *concurrencyController) observeError() {
+	c.windowErrors++
+}
+
+// decide sets the limit from the interval just ended. It makes no decision
+// if nothing completed in the interval.
+func (c *concurrencyController) decide(now time.Time) (metrics.ConcurrencyDecision, bool) {
+	total := len(c.window) + c.windowErrors
+	if total == 0 {
+		return metrics.ConcurrencyDecision{}, false
+	}
+	metrics.SortLatencies(c.window)
+	d := metrics.ConcurrencyDecision{
+		Timestamp:  now,
+		From:       c.limit,
+		LatencyP95: metrics.CalculatePercentile(c.window, 95),
+		ErrorRate:  float64(c.windowErrors) / float64(total) * 100,
+	}
+	c.window = c.window[:0]
+	c.windowErrors = 0
+
+	if c.p.Controller == config.ControllerPID {
+		d.Reason = c.pid(d.LatencyP95, d.ErrorRate)
+	} else {
+		d.Reason = c.aimd(d.LatencyP95, d.ErrorRate)
+	}
+	c.limit = max(c.p.Min, min(c.p.Max, c.limit))
+	d.To = c.limit
+	return d, true
+}
+
+// aimd adds workers while the goals are met and cuts them by a factor when
+// either is missed.
+func (c *concurrencyController) aimd(p95 time.Duration, errorRate float64) string {
+	var missed string
+	if c.p.TargetP95 > 0 && p95 > c.p.TargetP95 {
+		missed = fmt.Sprintf("p95 %s above %s", p95.Round(time.Millisecond), c.p.TargetP95)
+	} else if c.p.HasTargetErrorRate && errorRate > c.p.TargetErrorRate {
+		missed = fmt.Sprintf("error rate %.2f%% above %g%%", errorRate, c.p.TargetErrorRate)
+	}
+	if missed != "" {
+		c.limit = int(float64(c.limit) * c.p.Decrease)
+		return missed + ", decrease"
+	}
+	c.limit += c.p.Increase
+	return "within goal, increase"
+}
+
+// pid moves the limit in proportion to the relative distance from the goal,
+// in velocity form: the limit itself accumulates the integral term.
+func (c *concurrencyController) pid(p95 time.Duration, errorRate float64) string {
+	var e float64
+	var measured string
+	if c.p.TargetP95 > 0 {
+		e = float64(c.p.TargetP95-p95) / float64(c.p.TargetP95)
+		measured = fmt.Sprintf("p95 %s vs %s", p95.Round(time.Millisecond), c.p.TargetP95)
+	} else {
+		e = (c.p.TargetErrorRate - errorRate) / c.p.TargetErrorRate
+		measured = fmt.Sprintf("error rate %.2f%% vs %g%%", errorRate, c.p.TargetErrorRate)
+	}
+	e = math.Max(-1, math.Min(1, e))
+
+	dt := c.p.Interval.Seconds()
+	delta := c.p.Kp*(e-c.prevErr[0]) + c.p.Ki*e*dt + c.p.Kd*(e-2*c.prevErr[0]+c.prevErr[1])/dt
+	delta = math.Max(-0.5, math.Min(1, delta))
+	c.prevErr[1], c.prevErr[0] = c.prevErr[0], e
+
+	next := int(math.Round(float64(c.limit) * (1 + delta)))
+	// Always move by at least one worker so small limits are not stuck.
+	if next == c.limit && delta > 0.01 {
+		next++
+	} else if next == c.limit && delta < -0.01 {
+		next--
+	}
+	c.limit = next
+	return fmt.Sprintf("%s, adjust %+.0f%%", measured, delta*100)
+}
@@ -0,0 +1,186 @@
+package benchmark
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Th4phat/go-wrk/config"
+)
+
+// lateThreshold is how far behind its scheduled time an arrival may start
+// before it counts as late.
+const lateThreshold = 10 * time.Millisecond
+
+// arrivalSchedule generates the gaps between arrivals.
+type arrivalSchedule struct {
+	process string
+	mean    time.Duration   // Mean gap for poisson and constant arrivals
+	trace   []time.Duration // Gaps of a replayed trace
+	pos     int
+	rnd     *rand.Rand
+}
+
+// newArrivalSchedule prepares the schedule of an arrival model, reading its
+// trace file if it has one.
+func newArrivalSchedule(a config.ArrivalModel) (*arrivalSchedule, error) {
+	s := &arrivalSchedule{
+		process: a.ProcessName(),
+		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if a.Rate > 0 {
+		s.mean = time.Duration(float64(time.Second) / a.Rate)
+	}
+	if s.process != config.ArrivalTrace {
+		return s, nil
+	}
+
+	trace, err := readTrace(a.Trace)
+	if err != nil {
+		return nil, err
+	}
+	if a.Rate > 0 {
+		// Scale the trace to the requested mean rate, keeping its shape.
+		var total time.Duration
+		for _, gap := range trace {
+			total += gap
+		}
+		if total > 0 {
+			scale := float64(s.mean) * float64(len(trace)) / float64(total)
+			for i, gap := range trace {
+				trace[i] = time.Duration(float64(gap) * scale)
+			}
+		}
+	}
+	s.trace = trace
+	return s, nil
+}
+
+// readTrace reads inter-arrival gaps, one per line, as Go durations or as
+// seconds. Blank lines and lines starting with # are skipped.
+func readTrace(path string) ([]time.Duration, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading arrival trace: %w", err)
+	}
+	defer f.Close()
+
+	var trace []time.Duration
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		gap, err := time.ParseDuration(text)
+		if err != nil {
+			seconds, numErr := strconv.ParseFloat(text, 64)
+			if numErr != nil {
+				return nil, fmt.Errorf("arrival trace %s line %d: invalid gap %q", path, line, text)
+			}
+			gap = time.Duration(seconds * float64(time.Second))
+		}
+		if gap < 0 {
+			return nil, fmt.Errorf("arrival trace %s line %d: gap must not be negative", path, line)
+		}
+		trace = append(trace, gap)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading arrival trace: %w", err)
+	}
+	if len(trace) == 0 {
+		return nil, fmt.Errorf("arrival trace %s has no gaps", path)
+	}
+	return trace, nil
+}
+
+func (s *arrivalSchedule) next() time.Duration {
+	switch s.process {
+	case config.ArrivalTrace:
+		gap := s.trace[s.pos]
+		s.pos = (s.pos + 1) % len(s.trace)
+		return gap
+	case config.ArrivalConstant:
+		return s.mean
+	}
+	return time.Duration(s.rnd.ExpFloat64() * float64(s.mean))
+}
+
+// arrivalCounters counts arrivals that could not be sent on time.
+type arrivalCounters struct {
+	dropped atomic.Int64 // Skipped because max_in_flight requests were outstanding
+	late    atomic.Int64 // Sent more than lateThreshold after their scheduled time
+}
+
+// runArrivals starts requests on the schedule until ctx is done. A pool of
+// maxInFlight senders takes the arrivals; when all are busy an arrival is
+// dropped, or waits for a sender when queue is set.
+func runArrivals(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	driver Driver,
+	schedule *arrivalSchedule,
+	maxInFlight int,
+	queue bool,
+	counters *arrivalCounters,
+	resultsChan chan<- sample,
+	errorsChan chan<- sample,
+) {
+	defer wg.Done()
+
+	arrivals := make(chan time.Time)
+	var senders sync.WaitGroup
+	senders.Add(maxInFlight)
+	for i := 0; i < maxInFlight; i++ {
+		go func(senderID int) {
+			defer senders.Done()
+			for scheduled := range arrivals {
+				if time.Since(scheduled) > lateThreshold {
+					counters.late.Add(1)
+				}
+				doOperation(ctx, driver, senderID, resultsChan, errorsChan)
+			}
+		}(i)
+	}
+	defer senders.Wait()
+	defer close(arrivals)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+	next := time.Now()
+	for {
+		next = next.Add(schedule.next())
+		if wait := time.Until(next); wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+		} else if ctx.Err() != nil {
+			return
+		}
+
+		if queue {
+			select {
+			case arrivals <- next:
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+		select {
+		case arrivals <- next:
+		default:
+			counters.dropped.Add(1)
+		}
+	}
+}
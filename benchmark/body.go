@@ -0,0 +1,119 @@
+package benchmark
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Th4phat/go-wrk/config"
+)
+
+// buildBody returns cfg with its body encoded into Payload: the payload file
+// is read and form fields are encoded. It runs once per benchmark, so every
+// request sends the same bytes.
+func buildBody(cfg config.BenchmarkConfig) (config.BenchmarkConfig, error) {
+	switch {
+	case cfg.PayloadFile != "":
+		data, err := os.ReadFile(cfg.PayloadFile)
+		if err != nil {
+			return cfg, fmt.Errorf("reading payload file: %w", err)
+		}
+		cfg.Payload = string(data)
+		cfg.PayloadFile = ""
+
+	case len(cfg.Form) > 0 && strings.EqualFold(cfg.BodyType, config.BodyMultipart):
+		body, contentType, err := encodeMultipart(cfg.Form)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Payload = body
+		cfg.Form = nil
+		// The boundary must match the body, so it replaces any Content-Type
+		// set by the test.
+		headers := make(map[string]string, len(cfg.Headers)+1)
+		for name, value := range cfg.Headers {
+			if !strings.EqualFold(name, "Content-Type") {
+				headers[name] = value
+			}
+		}
+		headers["Content-Type"] = contentType
+		cfg.Headers = headers
+
+	case len(cfg.Form) > 0:
+		cfg.Payload = encodeForm(cfg.Form)
+		cfg.Form = nil
+	}
+	return cfg, nil
+}
+
+// encodeForm encodes fields as application/x-www-form-urlencoded, keeping
+// their order.
+func encodeForm(fields []config.FormField) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = url.QueryEscape(f.Name) + "=" + url.QueryEscape(f.Value)
+	}
+	return strings.Join(parts, "&")
+}
+
+// encodeMultipart encodes fields as multipart/form-data, reading file fields
+// from disk, and returns the body and its Content-Type.
+func encodeMultipart(fields []config.FormField) (string, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for _, f := range fields {
+		if f.File == "" && f.ContentType == "" {
+			if err := w.WriteField(f.Name, f.Value); err != nil {
+				return "", "", fmt.Errorf("encoding form field %q: %w", f.Name, err)
+			}
+			continue
+		}
+
+		content := []byte(f.Value)
+		filename := f.Filename
+		if f.File != "" {
+			data, err := os.ReadFile(f.File)
+			if err != nil {
+				return "", "", fmt.Errorf("reading file for form field %q: %w", f.Name, err)
+			}
+			content = data
+			if filename == "" {
+				filename = filepath.Base(f.File)
+			}
+		}
+		header := make(textproto.MIMEHeader)
+		disposition := fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(f.Name))
+		if filename != "" {
+			disposition += fmt.Sprintf(`; filename="%s"`, escapeQuotes(filename))
+		}
+		header.Set("Content-Disposition", disposition)
+		switch {
+		case f.ContentType != "":
+			header.Set("Content-Type", f.ContentType)
+		case f.File != "":
+			header.Set("Content-Type", "application/octet-stream")
+		}
+		part, err := w.CreatePart(header)
+		if err != nil {
+			return "", "", fmt.Errorf("encoding form field %q: %w", f.Name, err)
+		}
+		if _, err := part.Write(content); err != nil {
+			return "", "", fmt.Errorf("encoding form field %q: %w", f.Name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return "", "", fmt.Errorf("encoding multipart body: %w", err)
+	}
+	return buf.String(), w.FormDataContentType(), nil
+}
+
+var quoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
@@ -9,6 +9,7 @@ import (
 	// "os" // For debug prints if any
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Th4phat/go-wrk/config"
@@ -21,106 +22,137 @@ func runWorker(
 	ctx context.Context,
 	wg *sync.WaitGroup,
 	workerID int,
-	hostClient *fasthttp.HostClient,
-	cfg config.BenchmarkConfig,
-	resultsChan chan<- time.Duration,
-	errorsChan chan<- error,
+	driver Driver,
+	gate *concurrencyGate, // nil unless the concurrency is adaptive
+	resultsChan chan<- sample,
+	errorsChan chan<- sample,
 ) {
 	defer wg.Done()
 
-	req := fasthttp.AcquireRequest()
-	resp := fasthttp.AcquireResponse()
-	defer fasthttp.ReleaseRequest(req)
-	defer fasthttp.ReleaseResponse(resp)
-
-	req.SetRequestURI(cfg.TargetURL)
-	req.Header.SetMethod(cfg.Method)
-
-	var payloadBytes []byte
-	if (cfg.Method == "POST" || cfg.Method == "PUT" || cfg.Method == "PATCH") && cfg.Payload != "" {
-		req.Header.SetContentType("application/json")
-		payloadBytes = []byte(cfg.Payload)
-	}
-
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		default:
 		}
-
-		if payloadBytes != nil {
-			req.SetBody(payloadBytes)
+		if gate != nil && !gate.wait(ctx, workerID) {
+			return
 		}
 
-		reqStartTime := time.Now()
-		err := hostClient.Do(req, resp)
-		latency := time.Since(reqStartTime)
-
-		if err != nil {
-			if !(errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "context canceled")) {
-				select {
-				case <-ctx.Done():
-				case errorsChan <- err:
-				}
-			}
-			continue
-		}
+		doOperation(ctx, driver, workerID, resultsChan, errorsChan)
+	}
+}
 
-		statusCode := resp.StatusCode()
-		if statusCode >= 200 && statusCode < 300 {
-			select {
-			case resultsChan <- latency:
-			case <-ctx.Done():
-			}
+// errorKey names the kind of a request error for the error summary.
+func errorKey(err error) string {
+	errKey := "Unknown Error"
+	if errors.Is(err, fasthttp.ErrTimeout) {
+		errKey = "Timeout Error (fasthttp)"
+	} else if errors.Is(err, fasthttp.ErrConnectionClosed) {
+		errKey = "Connection Closed (fasthttp)"
+	} else if errors.Is(err, fasthttp.ErrNoFreeConns) {
+		errKey = "No Free Connections (fasthttp)"
+	} else if errors.Is(err, fasthttp.ErrPipelineOverflow) {
+		errKey = "Pipeline Overflow (fasthttp)"
+	} else if httpErr, ok := err.(*metrics.HttpStatusError); ok {
+		errKey = fmt.Sprintf("HTTP %d", httpErr.StatusCode)
+	} else if err != nil {
+		errStr := err.Error()
+		if errors.Is(err, context.Canceled) {
+			errKey = "Context Canceled"
+		} else if errors.Is(err, context.DeadlineExceeded) {
+			errKey = "Context Deadline Exceeded"
+		} else if strings.Contains(errStr, "connection refused") {
+			errKey = "Connection Refused"
+		} else if strings.Contains(errStr, "no such host") {
+			errKey = "DNS Error"
 		} else {
-			httpErr := &metrics.HttpStatusError{
-				StatusCode: statusCode,
-				Status:     string(resp.Header.StatusMessage()),
-			}
-			select {
-			case errorsChan <- httpErr:
-			case <-ctx.Done():
-			}
+			errKey = "Network Error"
 		}
 	}
+	return errKey
 }
 
 func (e *Engine) runCollector(
 	ctx context.Context,
 	cfg config.BenchmarkConfig,
-	hostClient *fasthttp.HostClient,
+	driver Driver,
+	schedule *arrivalSchedule, // nil unless cfg.Arrivals is set
 	progressChan chan<- metrics.ProgressUpdate,
 ) metrics.BenchmarkResult {
 	startTime := time.Now()
 
+	// workers is the number of goroutines sending requests. It is kept apart
+	// from cfg.Threads, which the result reports as configured.
+	var workers int
 	bufferFactor := 2
-	cfg.Threads = cfg.Threads * 20
-	if cfg.Threads > cfg.Connections && cfg.Connections > 0 {
-		bufferFactor = (cfg.Threads / cfg.Connections) * 2
+	if cfg.Users != nil {
+		// Each virtual user is one goroutine.
+		workers = cfg.Users.Count
+	} else if cfg.Arrivals != nil {
+		// One sender goroutine per request that may be in flight.
+		workers = cfg.Arrivals.MaxInFlight
+		if workers == 0 {
+			workers = cfg.Connections
+		}
+	} else if cfg.Adaptive != nil {
+		// Workers above the controller's limit are parked.
+		workers = cfg.Adaptive.Params(cfg.Connections).Max
+	} else {
+		workers = cfg.Threads * 20
+	}
+	if workers > cfg.Connections && cfg.Connections > 0 {
+		bufferFactor = (workers / cfg.Connections) * 2
 		if bufferFactor < 2 {
 			bufferFactor = 2
 		}
-	} else if cfg.Connections == 0 && cfg.Threads > 0 {
-		bufferFactor = cfg.Threads * 2
+	} else if cfg.Connections == 0 && workers > 0 {
+		bufferFactor = workers * 2
 	}
 	resultsChanBufferSize := cfg.Connections * bufferFactor
 	if resultsChanBufferSize < 100 {
 		resultsChanBufferSize = 100
 	}
-	if resultsChanBufferSize > 10000 && cfg.Threads < 1000 {
+	if resultsChanBufferSize > 10000 && workers < 1000 {
 		resultsChanBufferSize = 10000
 	}
 
-	resultsChan := make(chan time.Duration, resultsChanBufferSize)
-	errorsChan := make(chan error, resultsChanBufferSize)
+	resultsChan := make(chan sample, resultsChanBufferSize)
+	errorsChan := make(chan sample, resultsChanBufferSize)
 
 	var wgWorkers sync.WaitGroup
 	workersDoneChan := make(chan struct{})
 
-	wgWorkers.Add(cfg.Threads)
-	for i := 0; i < cfg.Threads; i++ {
-		go runWorker(ctx, &wgWorkers, i, hostClient, cfg, resultsChan, errorsChan)
+	var activeUsers atomic.Int64
+	var arrivals arrivalCounters
+
+	var gate *concurrencyGate
+	var controller *concurrencyController
+	var controlTick <-chan time.Time
+	var decisions, pendingDecisions []metrics.ConcurrencyDecision
+	if cfg.Adaptive != nil {
+		params := cfg.Adaptive.Params(cfg.Connections)
+		gate = newConcurrencyGate(params.Initial)
+		controller = newConcurrencyController(params)
+		stopRelease := context.AfterFunc(ctx, gate.release)
+		defer stopRelease()
+		controlTicker := time.NewTicker(params.Interval)
+		defer controlTicker.Stop()
+		controlTick = controlTicker.C
+	}
+	if schedule != nil {
+		queue := strings.EqualFold(cfg.Arrivals.OnFull, config.OnFullQueue)
+		wgWorkers.Add(1)
+		go runArrivals(ctx, &wgWorkers, driver, schedule, workers, queue, &arrivals, resultsChan, errorsChan)
+	} else {
+		wgWorkers.Add(workers)
+		for i := 0; i < workers; i++ {
+			if cfg.Users != nil {
+				go runVirtualUser(ctx, &wgWorkers, i, driver, cfg, &activeUsers, resultsChan, errorsChan)
+			} else {
+				go runWorker(ctx, &wgWorkers, i, driver, gate, resultsChan, errorsChan)
+			}
+		}
 	}
 
 	go func() {
@@ -128,10 +160,34 @@ func (e *Engine) runCollector(
 		close(workersDoneChan)
 	}()
 
+	// The main statistics cover only the measured phase; the warm-up and
+	// cool-down samples are kept apart.
+	warmup, measure, cooldown := cfg.Windows()
+	phases := newRunPhases(startTime, warmup, measure, cooldown)
+	var warmupSamples, cooldownSamples windowSamples
+	samplesFor := func(phase string) *windowSamples {
+		if phase == metrics.PhaseWarmup {
+			return &warmupSamples
+		}
+		return &cooldownSamples
+	}
+
 	requestsCompleted := 0
 	errorCount := 0
 	var latencyData []time.Duration
 	errorDetails := make(map[string]int)
+	series := newSeriesRecorder(startTime, driver)
+	recordLatency := func(s sample) {
+		series.observe(s.latency)
+		if phase := phases.at(s.sent); phase != metrics.PhaseMeasure {
+			w := samplesFor(phase)
+			w.completed++
+			w.latencies = append(w.latencies, s.latency)
+			return
+		}
+		requestsCompleted++
+		latencyData = append(latencyData, s.latency)
+	}
 
 	progressTicker := time.NewTicker(1 * time.Second)
 	defer progressTicker.Stop()
@@ -146,7 +202,7 @@ collectorLoop:
 				progressTicker.Stop()
 			}
 
-		case latency, ok := <-resultsChan:
+		case s, ok := <-resultsChan:
 			if !ok {
 				resultsChan = nil
 				if errorsChan == nil && workersDoneChan == nil {
@@ -154,10 +210,12 @@ collectorLoop:
 				}
 				continue
 			}
-			requestsCompleted++
-			latencyData = append(latencyData, latency)
+			if controller != nil {
+				controller.observe(s.latency)
+			}
+			recordLatency(s)
 
-		case err, ok := <-errorsChan:
+		case s, ok := <-errorsChan:
 			if !ok {
 				errorsChan = nil
 				if resultsChan == nil && workersDoneChan == nil {
@@ -165,69 +223,73 @@ collectorLoop:
 				}
 				continue
 			}
-			errorCount++
-			// ... (error key generation as before) ...
-			errKey := "Unknown Error"
-			if errors.Is(err, fasthttp.ErrTimeout) {
-				errKey = "Timeout Error (fasthttp)"
-			} else if errors.Is(err, fasthttp.ErrConnectionClosed) {
-				errKey = "Connection Closed (fasthttp)"
-			} else if errors.Is(err, fasthttp.ErrNoFreeConns) {
-				errKey = "No Free Connections (fasthttp)"
-			} else if errors.Is(err, fasthttp.ErrPipelineOverflow) {
-				errKey = "Pipeline Overflow (fasthttp)"
-			} else if httpErr, ok := err.(*metrics.HttpStatusError); ok {
-				errKey = fmt.Sprintf("HTTP %d", httpErr.StatusCode)
-			} else if err != nil {
-				errStr := err.Error()
-				if errors.Is(err, context.Canceled) {
-					errKey = "Context Canceled"
-				} else if errors.Is(err, context.DeadlineExceeded) {
-					errKey = "Context Deadline Exceeded"
-				} else if strings.Contains(errStr, "connection refused") {
-					errKey = "Connection Refused"
-				} else if strings.Contains(errStr, "no such host") {
-					errKey = "DNS Error"
-				} else {
-					errKey = "Network Error"
-				}
+			if controller != nil {
+				controller.observeError()
+			}
+			errKey := errorKey(s.err)
+			series.observeError(errKey)
+			if phase := phases.at(s.sent); phase != metrics.PhaseMeasure {
+				samplesFor(phase).errors++
+				continue
 			}
+			errorCount++
 			errorDetails[errKey]++
 
+		case now := <-controlTick:
+			if d, ok := controller.decide(now); ok && !contextAlreadyDone {
+				gate.set(d.To)
+				decisions = append(decisions, d)
+				pendingDecisions = append(pendingDecisions, d)
+			}
+
 		case <-progressTicker.C:
 			if !contextAlreadyDone {
 				now := time.Now()
-				elapsed := now.Sub(startTime)
-				currentAttempted := requestsCompleted + errorCount
+				seriesPoints := series.flush(now, phases.at(series.intervalStart))
+				// Report the samples of the phase the run is in.
+				phase := phases.at(now)
+				completed, errorsSeen, latencies := requestsCompleted, errorCount, latencyData
+				if phase != metrics.PhaseMeasure {
+					w := samplesFor(phase)
+					completed, errorsSeen, latencies = w.completed, w.errors, w.latencies
+				}
+				elapsed := now.Sub(phases.phaseStart(phase))
+				currentAttempted := completed + errorsSeen
 				var currentThroughput float64
 				if elapsed.Seconds() > 0.01 {
-					currentThroughput = float64(requestsCompleted) / elapsed.Seconds()
+					currentThroughput = float64(completed) / elapsed.Seconds()
 				}
 				var currentErrorRate float64
 				if currentAttempted > 0 {
-					currentErrorRate = float64(errorCount) / float64(currentAttempted) * 100
+					currentErrorRate = float64(errorsSeen) / float64(currentAttempted) * 100
 				}
 				var progressLatencySample []time.Duration
 				const maxProgressSample = 1000
-				if len(latencyData) > maxProgressSample {
+				if len(latencies) > maxProgressSample {
 					progressLatencySample = make([]time.Duration, maxProgressSample)
-					copy(progressLatencySample, latencyData[len(latencyData)-maxProgressSample:])
+					copy(progressLatencySample, latencies[len(latencies)-maxProgressSample:])
 				} else {
-					progressLatencySample = make([]time.Duration, len(latencyData))
-					copy(progressLatencySample, latencyData)
+					progressLatencySample = make([]time.Duration, len(latencies))
+					copy(progressLatencySample, latencies)
 				}
 				metrics.SortLatencies(progressLatencySample)
 				latencyAvg := metrics.CalculateAverage(progressLatencySample)
 				latencyP95 := metrics.CalculatePercentile(progressLatencySample, 95)
 				latencyP99 := metrics.CalculatePercentile(progressLatencySample, 99)
 				progressMsg := metrics.ProgressUpdate{
-					Timestamp: now, RequestsAttempted: currentAttempted, RequestsCompleted: requestsCompleted, Errors: errorCount,
+					Timestamp: now, RequestsAttempted: currentAttempted, RequestsCompleted: completed, Errors: errorsSeen,
 					CurrentThroughput: currentThroughput, CurrentErrorRate: currentErrorRate,
 					LatencyAvg: latencyAvg, LatencyP95: latencyP95, LatencyP99: latencyP99,
-					LatencyData: progressLatencySample,
+					LatencyData: progressLatencySample, ActiveUsers: int(activeUsers.Load()),
+					DroppedArrivals: int(arrivals.dropped.Load()), LateArrivals: int(arrivals.late.Load()),
+					Decisions: pendingDecisions, Phase: phase, Series: seriesPoints,
+				}
+				if gate != nil {
+					progressMsg.Concurrency = gate.get()
 				}
 				select {
 				case progressChan <- progressMsg:
+					pendingDecisions = nil
 				default:
 				}
 			}
@@ -240,17 +302,29 @@ collectorLoop:
 	close(resultsChan)
 	close(errorsChan)
 
-	for latency := range resultsChan {
-		requestsCompleted++
-		latencyData = append(latencyData, latency)
+	for s := range resultsChan {
+		recordLatency(s)
 	}
 
-	for _ = range errorsChan {
+	for s := range errorsChan {
+		series.observeError("Drained Error (Final Loop)")
+		if phase := phases.at(s.sent); phase != metrics.PhaseMeasure {
+			samplesFor(phase).errors++
+			continue
+		}
 		errorCount++
 		errorDetails["Drained Error (Final Loop)"]++
 	}
 	endTime := time.Now()
-	totalDuration := endTime.Sub(startTime)
+	// Close the last, partial interval unless it is empty and negligible.
+	if series.completed+series.errors > 0 || endTime.Sub(series.intervalStart) >= 100*time.Millisecond {
+		series.flush(endTime, phases.at(series.intervalStart))
+	}
+	measureEnd := endTime
+	if phases.cooldown && endTime.After(phases.measureEnd) {
+		measureEnd = phases.measureEnd
+	}
+	totalDuration := measureEnd.Sub(phases.measureStart)
 	finalAttempted := requestsCompleted + errorCount
 	if totalDuration < 1*time.Millisecond {
 		totalDuration = 1 * time.Millisecond
@@ -267,10 +341,10 @@ collectorLoop:
 	finalLatencyP99 := metrics.CalculatePercentile(latencyData, 99)
 	var finalError error
 	if ctx.Err() == context.Canceled {
-		finalError = fmt.Errorf("benchmark stopped by user")
+		finalError = fmt.Errorf("benchmark %w", ErrStopped)
 	} else if ctx.Err() == context.DeadlineExceeded {
 		if errorCount > 0 {
-			finalError = fmt.Errorf("benchmark completed with %d errors", errorCount)
+			finalError = &metrics.RequestErrors{Count: errorCount}
 		}
 	} else if errorCount > 0 {
 		finalError = fmt.Errorf("benchmark finished with %d errors (unknown reason for stop)", errorCount)
@@ -281,6 +355,21 @@ collectorLoop:
 		TotalDuration: totalDuration, Throughput: finalThroughput, ErrorRate: finalErrorRate,
 		LatencyAvg: finalLatencyAvg, LatencyP50: finalLatencyP50, LatencyP95: finalLatencyP95, LatencyP99: finalLatencyP99,
 		LatencyData: latencyData, ErrorDetails: errorDetails, Error: finalError,
+		DroppedArrivals: int(arrivals.dropped.Load()), LateArrivals: int(arrivals.late.Load()),
+		Decisions: decisions, Series: series.points,
+	}
+	if gate != nil {
+		finalResult.Concurrency = gate.get()
+	}
+	if warmup > 0 {
+		warmupEnd := phases.measureStart
+		if endTime.Before(warmupEnd) {
+			warmupEnd = endTime
+		}
+		finalResult.Warmup = warmupSamples.stats(startTime, warmupEnd)
+	}
+	if cooldown > 0 {
+		finalResult.Cooldown = cooldownSamples.stats(phases.measureEnd, endTime)
 	}
 	return finalResult
 }
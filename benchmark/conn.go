@@ -0,0 +1,52 @@
+package benchmark
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/valyala/fasthttp"
+)
+
+// connStats counts the bytes and connections of a run at the socket, so
+// TLS overhead is included.
+type connStats struct {
+	bytesIn  atomic.Int64
+	bytesOut atomic.Int64
+	open     atomic.Int64
+}
+
+// dial wraps a fasthttp dial function so its connections are counted.
+func (s *connStats) dial(dial fasthttp.DialFunc) fasthttp.DialFunc {
+	return func(addr string) (net.Conn, error) {
+		conn, err := dial(addr)
+		if err != nil {
+			return nil, err
+		}
+		s.open.Add(1)
+		return &countingConn{Conn: conn, stats: s}, nil
+	}
+}
+
+type countingConn struct {
+	net.Conn
+	stats     *connStats
+	closeOnce sync.Once
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.stats.bytesIn.Add(int64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.stats.bytesOut.Add(int64(n))
+	return n, err
+}
+
+func (c *countingConn) Close() error {
+	c.closeOnce.Do(func() { c.stats.open.Add(-1) })
+	return c.Conn.Close()
+}
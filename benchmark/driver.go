@@ -0,0 +1,132 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Th4phat/go-wrk/config"
+)
+
+// Driver sends the operations of a benchmark over one protocol. The engine
+// decides when each worker sends; the driver decides what sending means.
+//
+// Prepare is called once per run, before any worker starts. Do is then
+// called concurrently, but never concurrently for the same worker, so a
+// driver may keep per-worker state keyed by the worker number. Close is
+// called once the workers are done.
+type Driver interface {
+	// Prepare readies the driver for a run of cfg, whose secrets and body are
+	// already resolved.
+	Prepare(cfg config.BenchmarkConfig) error
+	// Do performs one operation. A nil error counts the operation as
+	// completed and its duration as its latency; errors are counted by kind.
+	Do(ctx context.Context, worker int) error
+	// Close releases what Prepare acquired.
+	Close() error
+	// Bytes returns the totals read and written since Prepare.
+	Bytes() (in, out int64)
+}
+
+// ConnectionCounter is implemented by drivers that can report how many
+// connections they hold open.
+type ConnectionCounter interface {
+	OpenConnections() int
+}
+
+// DriverFactory creates a driver for one run. client holds the options set
+// with Engine.SetClientOptions.
+type DriverFactory func(client ClientOptions) Driver
+
+// DefaultDriver is the driver of configs that do not name one.
+const DefaultDriver = "http"
+
+var (
+	driversMu sync.RWMutex
+	drivers   = map[string]DriverFactory{DefaultDriver: newHTTPDriver}
+)
+
+// RegisterDriver makes a driver available under name, as selected by the
+// driver field of a config. It panics if the name is taken, like
+// database/sql.Register.
+func RegisterDriver(name string, factory DriverFactory) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	name = strings.ToLower(name)
+	if factory == nil {
+		panic("benchmark: RegisterDriver factory is nil")
+	}
+	if _, dup := drivers[name]; dup {
+		panic("benchmark: RegisterDriver called twice for driver " + name)
+	}
+	drivers[name] = factory
+}
+
+// Drivers returns the names of the registered drivers, sorted.
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newDriver creates the driver a config selects.
+func newDriver(name string, client ClientOptions) (Driver, error) {
+	if name == "" {
+		name = DefaultDriver
+	}
+	driversMu.RLock()
+	factory, ok := drivers[strings.ToLower(name)]
+	driversMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown driver %q (registered: %s)", name, strings.Join(Drivers(), ", "))
+	}
+	return factory(client), nil
+}
+
+// sample is the outcome of one operation: its latency, or its error. It is
+// stamped with when the operation started, so it counts towards the phase
+// it was sent in however long the collector takes to receive it.
+type sample struct {
+	sent    time.Time
+	latency time.Duration
+	err     error
+}
+
+// doOperation has the driver perform one operation for worker and reports
+// its latency or error.
+func doOperation(
+	ctx context.Context,
+	driver Driver,
+	worker int,
+	resultsChan chan<- sample,
+	errorsChan chan<- sample,
+) {
+	start := time.Now()
+	err := driver.Do(ctx, worker)
+	latency := time.Since(start)
+
+	if err == nil {
+		select {
+		case resultsChan <- sample{sent: start, latency: latency}:
+		case <-ctx.Done():
+		}
+		return
+	}
+	// Operations cut short by the end of the run are not errors.
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "context canceled") {
+		return
+	}
+	select {
+	case errorsChan <- sample{sent: start, err: err}:
+	case <-ctx.Done():
+	}
+}
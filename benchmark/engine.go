@@ -2,8 +2,9 @@ package benchmark
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
-	"net/url"
 	"os"
 	"runtime/debug"
 	"sync"
@@ -11,12 +12,18 @@ import (
 
 	"github.com/Th4phat/go-wrk/config"
 	"github.com/Th4phat/go-wrk/metrics"
+	"github.com/Th4phat/go-wrk/secret"
 
 	"github.com/valyala/fasthttp"
 )
 
+// ErrStopped is wrapped by the error of a result when the run was ended
+// early with Stop.
+var ErrStopped = errors.New("stopped by user")
+
 type Engine struct {
 	status Status
+	client ClientOptions
 
 	stopSignal chan struct{}
 	wgGlobal   sync.WaitGroup
@@ -39,6 +46,27 @@ func NewEngine() *Engine {
 	}
 }
 
+// ClientOptions tune the client of the runs an engine starts. The HTTP
+// driver uses them all; other drivers use what applies to them. Zero fields
+// keep the defaults.
+type ClientOptions struct {
+	ReadTimeout        time.Duration     // Default 30s
+	WriteTimeout       time.Duration     // Default 10s
+	MaxConnWaitTimeout time.Duration     // How long a worker waits for a free connection; default 30s
+	TLSConfig          *tls.Config       // Cloned per run; the config's insecure flag still applies
+	Dial               fasthttp.DialFunc // Opens connections; default a TCP dialer with a DNS cache
+}
+
+// SetClientOptions sets the client options of the next run.
+func (e *Engine) SetClientOptions(o ClientOptions) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.client = o
+}
+
+// Start runs a benchmark in the background. Once it returns nil, the engine
+// owns progressChan and resultChan: it sends the result and closes both when
+// the run ends. On error it leaves them open for the caller to close.
 func (e *Engine) Start(
 	cfg config.BenchmarkConfig,
 	progressChan chan<- metrics.ProgressUpdate,
@@ -51,34 +79,34 @@ func (e *Engine) Start(
 	}
 	e.status = StatusRunning
 	e.stopSignal = make(chan struct{})
+	client := e.client
 	e.mu.Unlock()
 
-	parsedURL, err := url.Parse(cfg.TargetURL)
+	// Secrets are resolved into a copy used only for sending requests; the
+	// result keeps the references so values never reach the TUI or reports.
+	runCfg, err := secret.ResolveConfig(cfg)
+	if err == nil {
+		runCfg, err = buildBody(runCfg)
+	}
+	var schedule *arrivalSchedule
+	if err == nil && runCfg.Arrivals != nil {
+		schedule, err = newArrivalSchedule(*runCfg.Arrivals)
+	}
+	var driver Driver
+	if err == nil {
+		driver, err = newDriver(runCfg.Driver, client)
+	}
+	if err == nil {
+		if err = driver.Prepare(runCfg); err != nil && runCfg.TargetURL != cfg.TargetURL {
+			// Name the URL by its references, never by its resolved value.
+			err = fmt.Errorf("%s: %w", cfg.TargetURL, err)
+		}
+	}
 	if err != nil {
 		e.mu.Lock()
 		e.status = StatusIdle
 		e.mu.Unlock()
-		close(progressChan)
-		close(resultChan)
-		return fmt.Errorf("invalid target URL for fasthttp client: %w", err)
-	}
-	isTLS := parsedURL.Scheme == "https"
-
-	hostClient := &fasthttp.HostClient{
-		Addr:     parsedURL.Host,
-		Name:     "github.com/Th4phat/go-wrk-fasthttp-client",
-		MaxConns: cfg.Connections,
-
-		ReadTimeout:                   30 * time.Second,
-		WriteTimeout:                  10 * time.Second,
-		MaxIdleConnDuration:           90 * time.Second,
-		IsTLS:                         isTLS,
-		NoDefaultUserAgentHeader:      true,
-		DisableHeaderNamesNormalizing: true,
-		Dial: (&fasthttp.TCPDialer{
-			Concurrency:      4096,
-			DNSCacheDuration: time.Hour,
-		}).Dial,
+		return err
 	}
 
 	duration, err := time.ParseDuration(cfg.Duration)
@@ -86,11 +114,18 @@ func (e *Engine) Start(
 		e.mu.Lock()
 		e.status = StatusIdle
 		e.mu.Unlock()
-		close(progressChan)
-		close(resultChan)
+		driver.Close()
 		return fmt.Errorf("invalid duration format in config: %w", err)
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if runCfg.Search != nil {
+		// Every step of a capacity search has its own deadline.
+		ctx, cancel = context.WithCancel(context.Background())
+	} else {
+		warmup, _, cooldown := runCfg.Windows()
+		ctx, cancel = context.WithTimeout(context.Background(), warmup+duration+cooldown)
+	}
 
 	go func() {
 		<-e.stopSignal
@@ -101,6 +136,7 @@ func (e *Engine) Start(
 	go func() {
 		defer e.wgGlobal.Done()
 		defer cancel()
+		defer driver.Close()
 		defer close(progressChan)
 		defer close(resultChan)
 
@@ -122,18 +158,22 @@ func (e *Engine) Start(
 			e.mu.Unlock()
 		}()
 
-		finalResult = e.runCollector(ctx, cfg, hostClient, progressChan)
+		if runCfg.Search != nil {
+			finalResult = e.runSearch(ctx, runCfg, driver, progressChan)
+		} else {
+			finalResult = e.runCollector(ctx, runCfg, driver, schedule, progressChan)
+		}
+		if finalResult.Config != nil {
+			masked := *finalResult.Config
+			masked.TargetURL, masked.Headers, masked.Payload, masked.Mix = cfg.TargetURL, cfg.Headers, cfg.Payload, cfg.Mix
+			masked.PayloadFile, masked.Form = cfg.PayloadFile, cfg.Form
+			finalResult.Config = &masked
+		}
 
+		// The run context is always done by the time the collector returns, so
+		// only the timeout guards this send.
 		select {
 		case resultChan <- finalResult:
-		case <-ctx.Done():
-			if finalResult.Error == nil {
-				finalResult.Error = fmt.Errorf("benchmark interrupted before final result sent")
-				select {
-				case resultChan <- finalResult:
-				default:
-				}
-			}
 		case <-time.After(1 * time.Second):
 			if finalResult.Error == nil {
 				finalResult.Error = fmt.Errorf("timed out sending final result to TUI")
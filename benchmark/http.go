@@ -0,0 +1,131 @@
+package benchmark
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Th4phat/go-wrk/config"
+	"github.com/Th4phat/go-wrk/metrics"
+
+	"github.com/valyala/fasthttp"
+)
+
+// httpDriver sends HTTP/1.1 requests with fasthttp. It is the default driver.
+type httpDriver struct {
+	client     ClientOptions
+	cfg        config.BenchmarkConfig
+	hostClient *fasthttp.HostClient
+	conns      connStats
+	workers    sync.Map // Worker number to *httpWorker
+}
+
+// httpWorker holds the prepared requests and response buffer of one worker.
+type httpWorker struct {
+	mix  *requestMix
+	resp *fasthttp.Response
+}
+
+func newHTTPDriver(client ClientOptions) Driver {
+	return &httpDriver{client: client}
+}
+
+func (d *httpDriver) Prepare(cfg config.BenchmarkConfig) error {
+	parsedURL, err := url.Parse(cfg.TargetURL)
+	if err != nil {
+		// The URL has its secrets resolved, so only the reason is reported.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("invalid target URL for fasthttp client: %w", err)
+	}
+	d.cfg = cfg
+	d.hostClient = &fasthttp.HostClient{
+		Addr:     parsedURL.Host,
+		Name:     "github.com/Th4phat/go-wrk-fasthttp-client",
+		MaxConns: cfg.Connections,
+
+		ReadTimeout:         cmp.Or(d.client.ReadTimeout, 30*time.Second),
+		WriteTimeout:        cmp.Or(d.client.WriteTimeout, 10*time.Second),
+		MaxIdleConnDuration: 90 * time.Second,
+		MaxConnWaitTimeout:  cmp.Or(d.client.MaxConnWaitTimeout, 30*time.Second), // Workers outnumber connections; queue instead of failing with ErrNoFreeConns
+
+		IsTLS:                         parsedURL.Scheme == "https",
+		TLSConfig:                     &tls.Config{InsecureSkipVerify: cfg.SkipVerify()},
+		NoDefaultUserAgentHeader:      true,
+		DisableHeaderNamesNormalizing: true,
+	}
+	if d.client.TLSConfig != nil {
+		d.hostClient.TLSConfig = d.client.TLSConfig.Clone()
+		d.hostClient.TLSConfig.InsecureSkipVerify = d.hostClient.TLSConfig.InsecureSkipVerify || cfg.SkipVerify()
+	}
+	dial := d.client.Dial
+	if dial == nil {
+		dial = (&fasthttp.TCPDialer{
+			Concurrency:      4096,
+			DNSCacheDuration: time.Hour,
+		}).Dial
+	}
+	d.hostClient.Dial = d.conns.dial(dial)
+	return nil
+}
+
+func (d *httpDriver) worker(id int) *httpWorker {
+	if w, ok := d.workers.Load(id); ok {
+		return w.(*httpWorker)
+	}
+	w := &httpWorker{mix: newRequestMix(d.cfg, id), resp: fasthttp.AcquireResponse()}
+	d.workers.Store(id, w)
+	return w
+}
+
+func (d *httpDriver) Do(ctx context.Context, worker int) error {
+	w := d.worker(worker)
+	req, payloadBytes := w.mix.next()
+	if payloadBytes != nil {
+		req.SetBody(payloadBytes)
+	}
+
+	// A HEAD response has no body even when it announces a Content-Length.
+	// The response is reused across the mix, so reset the flag each time.
+	w.resp.SkipBody = req.Header.IsHead()
+
+	if err := d.hostClient.Do(req, w.resp); err != nil {
+		return err
+	}
+	if statusCode := w.resp.StatusCode(); statusCode < 200 || statusCode >= 300 {
+		return &metrics.HttpStatusError{
+			StatusCode: statusCode,
+			Status:     string(w.resp.Header.StatusMessage()),
+		}
+	}
+	return nil
+}
+
+func (d *httpDriver) Close() error {
+	d.workers.Range(func(_, v any) bool {
+		w := v.(*httpWorker)
+		w.mix.release()
+		fasthttp.ReleaseResponse(w.resp)
+		return true
+	})
+	d.workers.Clear()
+	if d.hostClient != nil {
+		d.hostClient.CloseIdleConnections()
+	}
+	return nil
+}
+
+func (d *httpDriver) Bytes() (in, out int64) {
+	return d.conns.bytesIn.Load(), d.conns.bytesOut.Load()
+}
+
+func (d *httpDriver) OpenConnections() int {
+	return int(d.conns.open.Load())
+}
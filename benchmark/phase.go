@@ -0,0 +1,58 @@
+package benchmark
+
+import (
+	"time"
+
+	"github.com/Th4phat/go-wrk/metrics"
+)
+
+// runPhases tells which phase of a run a moment falls in.
+type runPhases struct {
+	start        time.Time // Start of the warm-up
+	measureStart time.Time
+	measureEnd   time.Time
+	cooldown     bool // Samples after measureEnd belong to the cool-down
+}
+
+func newRunPhases(start time.Time, warmup, measure, cooldown time.Duration) runPhases {
+	measureStart := start.Add(warmup)
+	return runPhases{start: start, measureStart: measureStart, measureEnd: measureStart.Add(measure), cooldown: cooldown > 0}
+}
+
+func (p runPhases) at(t time.Time) string {
+	switch {
+	case t.Before(p.measureStart):
+		return metrics.PhaseWarmup
+	case p.cooldown && !t.Before(p.measureEnd):
+		return metrics.PhaseCooldown
+	}
+	return metrics.PhaseMeasure
+}
+
+// phaseStart returns when the phase began.
+func (p runPhases) phaseStart(phase string) time.Time {
+	switch phase {
+	case metrics.PhaseWarmup:
+		return p.start
+	case metrics.PhaseCooldown:
+		return p.measureEnd
+	}
+	return p.measureStart
+}
+
+// windowSamples collects the samples of a warm-up or cool-down window, kept
+// apart from the measured statistics.
+type windowSamples struct {
+	completed int
+	errors    int
+	latencies []time.Duration
+}
+
+// stats summarizes the window between from and to, or returns nil if the run
+// never reached it.
+func (w *windowSamples) stats(from, to time.Time) *metrics.PhaseStats {
+	if !to.After(from) {
+		return nil
+	}
+	return metrics.NewPhaseStats(w.completed, w.errors, w.latencies, to.Sub(from))
+}
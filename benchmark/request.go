@@ -0,0 +1,109 @@
+package benchmark
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Th4phat/go-wrk/config"
+
+	"github.com/valyala/fasthttp"
+)
+
+// prepareRequest sets the URI, method and headers of req from cfg and returns
+// the body to send with every request, or nil if there is none.
+func prepareRequest(req *fasthttp.Request, cfg config.BenchmarkConfig) []byte {
+	req.SetRequestURI(cfg.TargetURL)
+	method := cfg.Method
+	if strings.EqualFold(method, fasthttp.MethodHead) {
+		// fasthttp only skips the response body of an upper-case HEAD.
+		method = fasthttp.MethodHead
+	}
+	req.Header.SetMethod(method)
+
+	for name, value := range cfg.Headers {
+		req.Header.Set(name, value)
+	}
+
+	var payloadBytes []byte
+	if cfg.Payload != "" {
+		if len(req.Header.ContentType()) == 0 {
+			req.Header.SetContentType(config.BodyContentType(cfg.BodyType))
+		}
+		payloadBytes = []byte(cfg.Payload)
+	}
+	return payloadBytes
+}
+
+// requestMix holds the prepared requests of one worker and picks the next one
+// by weight. A config without a mix has a single request.
+type requestMix struct {
+	reqs     []*fasthttp.Request
+	payloads [][]byte
+	cumul    []int
+	total    int
+	rnd      *rand.Rand
+}
+
+func newRequestMix(cfg config.BenchmarkConfig, workerID int) *requestMix {
+	m := &requestMix{rnd: rand.New(rand.NewSource(time.Now().UnixNano() + int64(workerID)))}
+	if len(cfg.Mix) == 0 {
+		m.add(cfg, 1)
+		return m
+	}
+	for i, spec := range cfg.Mix {
+		m.add(cfg.MixRequest(i), spec.Weight)
+	}
+	return m
+}
+
+func (m *requestMix) add(cfg config.BenchmarkConfig, weight int) {
+	req := fasthttp.AcquireRequest()
+	m.payloads = append(m.payloads, prepareRequest(req, cfg))
+	m.reqs = append(m.reqs, req)
+	m.total += weight
+	m.cumul = append(m.cumul, m.total)
+}
+
+func (m *requestMix) next() (*fasthttp.Request, []byte) {
+	if len(m.reqs) == 1 {
+		return m.reqs[0], m.payloads[0]
+	}
+	n := m.rnd.Intn(m.total)
+	i := sort.SearchInts(m.cumul, n+1)
+	return m.reqs[i], m.payloads[i]
+}
+
+func (m *requestMix) release() {
+	for _, req := range m.reqs {
+		fasthttp.ReleaseRequest(req)
+	}
+}
+
+// RawRequest returns the HTTP/1.1 request bytes the workers send for cfg.
+func RawRequest(cfg config.BenchmarkConfig) ([]byte, error) {
+	cfg, err := buildBody(cfg)
+	if err != nil {
+		return nil, err
+	}
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	if payload := prepareRequest(req, cfg); payload != nil {
+		req.SetBody(payload)
+	}
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	if err := req.Write(w); err != nil {
+		return nil, fmt.Errorf("serializing request: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		return nil, fmt.Errorf("serializing request: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,154 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Th4phat/go-wrk/config"
+	"github.com/Th4phat/go-wrk/metrics"
+)
+
+// minStepThroughput is the fraction of its rate a search step must achieve
+// to pass.
+const minStepThroughput = 0.9
+
+// runSearch runs a capacity search: fixed-rate steps that ramp up until the
+// SLO breaks, then bisect between the last passing and first failing rates.
+// The result holds the statistics of the highest passing step.
+func (e *Engine) runSearch(
+	ctx context.Context,
+	cfg config.BenchmarkConfig,
+	driver Driver,
+	progressChan chan<- metrics.ProgressUpdate,
+) metrics.BenchmarkResult {
+	search := *cfg.Search
+	stepDuration, _ := time.ParseDuration(cfg.Duration)
+	if search.StepDuration != "" {
+		stepDuration, _ = time.ParseDuration(search.StepDuration)
+	}
+
+	state := &metrics.SearchResult{}
+	var best, last metrics.BenchmarkResult
+	var stepErr error
+
+	// runStep runs one step at rate and reports whether it passed. ok is
+	// false if the search was stopped or the step could not run.
+	runStep := func(rate float64) (passed, ok bool) {
+		arrivals := config.ArrivalModel{Process: config.ArrivalConstant}
+		if cfg.Arrivals != nil {
+			arrivals = *cfg.Arrivals
+		}
+		arrivals.Rate = rate
+		stepCfg := cfg
+		stepCfg.Arrivals = &arrivals
+		stepCfg.Duration = stepDuration.String()
+		schedule, err := newArrivalSchedule(arrivals)
+		if err != nil {
+			stepErr = err
+			return false, false
+		}
+
+		state.CurrentRate = rate
+		// Each step has its own warm-up and cool-down.
+		warmup, _, cooldown := cfg.Windows()
+		stepCtx, cancel := context.WithTimeout(ctx, warmup+stepDuration+cooldown)
+		defer cancel()
+
+		// Forward the step's progress with the search so far.
+		stepProgress := make(chan metrics.ProgressUpdate, 1)
+		forwarded := make(chan struct{})
+		go func() {
+			defer close(forwarded)
+			for p := range stepProgress {
+				p.Search = state.Clone()
+				select {
+				case progressChan <- p:
+				default:
+				}
+			}
+		}()
+		result := e.runCollector(stepCtx, stepCfg, driver, schedule, stepProgress)
+		close(stepProgress)
+		<-forwarded
+
+		if ctx.Err() != nil {
+			return false, false
+		}
+		step := metrics.SearchStep{
+			Rate: rate, Throughput: result.Throughput, ErrorRate: result.ErrorRate,
+			LatencyP50: result.LatencyP50, LatencyP99: result.LatencyP99, Dropped: result.DroppedArrivals,
+		}
+		step.Violations = result.Violations(search.SLO)
+		if step.Dropped > 0 {
+			step.Violations = append(step.Violations, fmt.Sprintf("%d arrivals dropped at the in-flight cap", step.Dropped))
+		}
+		if result.Throughput < minStepThroughput*rate {
+			step.Violations = append(step.Violations, fmt.Sprintf("throughput %.1f req/s is below %g req/s", result.Throughput, rate))
+		}
+		step.Passed = len(step.Violations) == 0
+		state.Steps = append(state.Steps, step)
+		last = result
+		if step.Passed && rate >= state.SustainableRate {
+			state.SustainableRate, state.SustainableThroughput = rate, result.Throughput
+			best = result
+		}
+		return step.Passed, true
+	}
+	stepsLeft := func() bool {
+		return search.MaxSteps == 0 || len(state.Steps) < search.MaxSteps
+	}
+
+	// Ramp up until a step fails or the maximum rate passes.
+	var passing, failing float64
+	for rate := search.StartRate; stepsLeft(); rate *= search.GrowthFactor() {
+		if search.MaxRate > 0 && rate > search.MaxRate {
+			rate = search.MaxRate
+		}
+		passed, ok := runStep(rate)
+		if !ok {
+			break
+		}
+		if !passed {
+			failing = rate
+			break
+		}
+		passing = rate
+		if rate == search.MaxRate {
+			break
+		}
+	}
+
+	// Bisect to the knee. A failing first step leaves nothing to bisect.
+	for passing > 0 && failing > 0 && failing-passing > search.PrecisionFraction()*failing && stepsLeft() && ctx.Err() == nil {
+		rate := (passing + failing) / 2
+		passed, ok := runStep(rate)
+		if !ok {
+			break
+		}
+		if passed {
+			passing = rate
+		} else {
+			failing = rate
+		}
+	}
+	state.CurrentRate = 0
+
+	result := best
+	if state.SustainableRate == 0 {
+		result = last
+	}
+	result.Config = &cfg
+	result.Search = state
+	result.Error = nil
+	switch {
+	case stepErr != nil:
+		result.Error = stepErr
+	case ctx.Err() == context.Canceled:
+		result.Error = fmt.Errorf("capacity search %w", ErrStopped)
+	case len(state.Steps) > 0 && state.SustainableRate == 0:
+		result.Error = fmt.Errorf("no step met the SLO: %g req/s already fails (%s)", search.StartRate, strings.Join(state.Steps[0].Violations, "; "))
+	}
+	return result
+}
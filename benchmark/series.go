@@ -0,0 +1,77 @@
+package benchmark
+
+import (
+	"time"
+
+	"github.com/Th4phat/go-wrk/metrics"
+)
+
+// seriesRecorder collects the samples of the current interval and closes it
+// into a point of the run's time series.
+type seriesRecorder struct {
+	driver        Driver
+	start         time.Time
+	intervalStart time.Time
+	points        []metrics.SeriesPoint
+
+	latencies    []time.Duration
+	completed    int
+	errors       int
+	errorsByType map[string]int
+	bytesIn      int64 // Socket totals when the interval started
+	bytesOut     int64
+}
+
+func newSeriesRecorder(start time.Time, driver Driver) *seriesRecorder {
+	r := &seriesRecorder{driver: driver, start: start, intervalStart: start}
+	// The counters may carry over from earlier steps of a search.
+	r.bytesIn, r.bytesOut = driver.Bytes()
+	return r
+}
+
+func (r *seriesRecorder) observe(latency time.Duration) {
+	r.completed++
+	r.latencies = append(r.latencies, latency)
+}
+
+func (r *seriesRecorder) observeError(key string) {
+	r.errors++
+	if r.errorsByType == nil {
+		r.errorsByType = make(map[string]int)
+	}
+	r.errorsByType[key]++
+}
+
+// flush closes the interval ending at now. The returned slice must not be
+// modified.
+func (r *seriesRecorder) flush(now time.Time, phase string) []metrics.SeriesPoint {
+	duration := now.Sub(r.intervalStart)
+	metrics.SortLatencies(r.latencies)
+	p := metrics.SeriesPoint{
+		Offset:       r.intervalStart.Sub(r.start),
+		Duration:     duration,
+		Phase:        phase,
+		Completed:    r.completed,
+		Errors:       r.errors,
+		ErrorsByType: r.errorsByType,
+		LatencyP50:   metrics.CalculatePercentile(r.latencies, 50),
+		LatencyP90:   metrics.CalculatePercentile(r.latencies, 90),
+		LatencyP99:   metrics.CalculatePercentile(r.latencies, 99),
+		Histogram:    metrics.NewLatencyHistogram(r.latencies),
+	}
+	if duration > 0 {
+		p.Throughput = float64(r.completed) / duration.Seconds()
+	}
+	bytesIn, bytesOut := r.driver.Bytes()
+	p.BytesIn, p.BytesOut = bytesIn-r.bytesIn, bytesOut-r.bytesOut
+	r.bytesIn, r.bytesOut = bytesIn, bytesOut
+	if counter, ok := r.driver.(ConnectionCounter); ok {
+		p.OpenConnections = counter.OpenConnections()
+	}
+	r.points = append(r.points, p)
+
+	r.intervalStart = now
+	r.latencies = r.latencies[:0]
+	r.completed, r.errors, r.errorsByType = 0, 0, nil
+	return r.points[:len(r.points):len(r.points)]
+}
@@ -0,0 +1,105 @@
+package benchmark
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Th4phat/go-wrk/config"
+)
+
+// thinkSampler draws think times from a distribution.
+type thinkSampler struct {
+	params config.ThinkTimeParams
+}
+
+func newThinkSampler(t *config.ThinkTime) *thinkSampler {
+	if t == nil {
+		return nil
+	}
+	// The config was validated before the run started.
+	params, _ := t.Params("")
+	return &thinkSampler{params: params}
+}
+
+func (s *thinkSampler) next(rnd *rand.Rand) time.Duration {
+	if s == nil {
+		return 0
+	}
+	p := s.params
+	var d time.Duration
+	switch p.Distribution {
+	case config.ThinkUniform:
+		d = p.Min + time.Duration(rnd.Int63n(int64(p.Max-p.Min)+1))
+	case config.ThinkNormal:
+		d = p.Mean + time.Duration(rnd.NormFloat64()*float64(p.StdDev))
+	case config.ThinkExponential:
+		d = time.Duration(rnd.ExpFloat64() * float64(p.Mean))
+	default:
+		d = p.Mean
+	}
+	return max(d, 0)
+}
+
+// sleepCtx waits for d, returning false if ctx is done first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// runVirtualUser sends one request per iteration, then thinks or waits for
+// its next paced iteration. Users start at random offsets within their first
+// interval so they do not arrive in lockstep.
+func runVirtualUser(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	userID int,
+	driver Driver,
+	cfg config.BenchmarkConfig,
+	activeUsers *atomic.Int64,
+	resultsChan chan<- sample,
+	errorsChan chan<- sample,
+) {
+	defer wg.Done()
+
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano() + int64(userID)))
+	think := newThinkSampler(cfg.Users.ThinkTime)
+	pacing, _ := time.ParseDuration(cfg.Users.Pacing)
+
+	startOffset := think.next(rnd)
+	if pacing > 0 {
+		startOffset = time.Duration(rnd.Int63n(int64(pacing)))
+	} else if startOffset > 0 {
+		startOffset = time.Duration(rnd.Int63n(int64(startOffset)))
+	}
+	if !sleepCtx(ctx, startOffset) {
+		return
+	}
+
+	activeUsers.Add(1)
+	defer activeUsers.Add(-1)
+
+	for ctx.Err() == nil {
+		iterationStart := time.Now()
+		doOperation(ctx, driver, userID, resultsChan, errorsChan)
+
+		wait := think.next(rnd)
+		if pacing > 0 {
+			wait = pacing - time.Since(iterationStart)
+		}
+		if !sleepCtx(ctx, wait) {
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+var commands = map[string]command{
+	"target":    {summary: "Start a local HTTP target server with artificial latency and errors", run: runTarget},
+	"calibrate": {summary: "Benchmark a local target to measure this machine's maximum RPS", run: runCalibrate},
+	"heatmap":   {summary: "Render the latency heatmaps of a saved result as HTML or SVG", run: runHeatmap},
+	"import":    {summary: "Import tests from other formats (curl, openapi, postman, har)", run: runImport},
+	"report":    {summary: "Render a saved result as a self-contained HTML report", run: runReport},
+	"run":       {summary: "Run tests without the TUI and save their results", run: runRun},
+	"secrets":   {summary: "Manage the encrypted store for ${secret:NAME} references", run: runSecrets},
+	"validate":  {summary: "Check JSON and YAML test files against the schema", run: runValidate},
+}
+
+// IsCommand reports whether name is a known subcommand.
+func IsCommand(name string) bool {
+	if name == "help" || name == "-h" || name == "--help" {
+		return true
+	}
+	_, ok := commands[name]
+	return ok
+}
+
+// Run executes the subcommand named by args[0] and returns the process exit code.
+func Run(args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(os.Stdout)
+		return 0
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q.\n\n", args[0])
+		printUsage(os.Stderr)
+		return 2
+	}
+	if err := cmd.run(args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: go-wrk [command] [flags]")
+	fmt.Fprintln(w, "\nWithout a command, go-wrk starts the interactive TUI. Use --env NAME to")
+	fmt.Fprintln(w, "select an environment defined in collection files.")
+	fmt.Fprintln(w, "\nCommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	width := 0
+	for _, name := range names {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s%s  %s\n", name, strings.Repeat(" ", width-len(name)), commands[name].summary)
+	}
+	fmt.Fprintln(w, "\nRun 'go-wrk <command> -h' for command flags.")
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Th4phat/go-wrk/metrics"
+	"github.com/Th4phat/go-wrk/report"
+)
+
+func runHeatmap(args []string) error {
+	flags := flag.NewFlagSet("heatmap", flag.ContinueOnError)
+	output := flags.String("o", "", "output file; .svg writes the bare SVG of a single test (default: RESULT-heatmap.html)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-wrk heatmap [-o FILE] RESULT.json")
+		fmt.Fprintln(flags.Output(), "\nRenders the latency heatmap of each test in a result file saved by 'go-wrk run -o'")
+		fmt.Fprintln(flags.Output(), "or by Ctrl+S in the TUI.")
+		flags.PrintDefaults()
+	}
+	paths, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(paths) != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected one result file")
+	}
+	run, err := metrics.ReadRunFile(paths[0])
+	if err != nil {
+		return err
+	}
+
+	path := *output
+	if path == "" {
+		path = strings.TrimSuffix(paths[0], filepath.Ext(paths[0])) + "-heatmap.html"
+	}
+	var buf bytes.Buffer
+	if strings.EqualFold(filepath.Ext(path), ".svg") {
+		if len(run.Tests) != 1 {
+			return fmt.Errorf("%s holds %d tests; an SVG holds one, write HTML instead", paths[0], len(run.Tests))
+		}
+		svg := report.HeatmapSVG(run.Tests[0].Result.Series)
+		if svg == "" {
+			return fmt.Errorf("%s has no per-second latency data", paths[0])
+		}
+		buf.WriteString(svg)
+	} else if err := report.WriteHeatmapHTML(&buf, run); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Heatmap written to %s\n", path)
+	return nil
+}
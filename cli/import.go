@@ -0,0 +1,331 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Th4phat/go-wrk/config"
+	"github.com/Th4phat/go-wrk/convert"
+)
+
+var importers = map[string]command{
+	"curl":    {summary: "Import a curl command line as a test", run: runImportCurl},
+	"openapi": {summary: "Generate a collection from an OpenAPI 3 specification", run: runImportOpenAPI},
+	"postman": {summary: "Import a Postman v2.1 collection, one go-wrk collection per folder", run: runImportPostman},
+	"har":     {summary: "Import a HAR capture as a weighted request mix or a collection", run: runImportHAR},
+}
+
+// parseInterspersed parses flags that may appear before or after positional
+// arguments, as in "go-wrk import openapi spec.yaml --base-url ...".
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// saveImported saves an imported test, replacing an existing test of the same
+// name only if force is set.
+func saveImported(collection, name string, cfg config.BenchmarkConfig, force bool) error {
+	save := config.SaveTestToCollection
+	if force {
+		save = config.ReplaceTestInCollection
+	}
+	err := save(config.GetConfigDir(), collection, name, cfg)
+	if errors.Is(err, config.ErrTestExists) {
+		return fmt.Errorf("%w; use -force to overwrite", err)
+	}
+	return err
+}
+
+func runImport(args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		names := make([]string, 0, len(importers))
+		for name := range importers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Println("Usage: go-wrk import <format> [flags] <source>")
+		fmt.Println("\nFormats:")
+		for _, name := range names {
+			fmt.Printf("  %-8s  %s\n", name, importers[name].summary)
+		}
+		return nil
+	}
+	imp, ok := importers[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown import format %q", args[0])
+	}
+	return imp.run(args[1:])
+}
+
+func runImportCurl(args []string) error {
+	fs := flag.NewFlagSet("import curl", flag.ContinueOnError)
+	collection := fs.String("collection", "imported", "collection to save the test into")
+	name := fs.String("name", "", "test name (derived from method and URL if empty)")
+	file := fs.String("file", "", "read the curl command from a file ('-' for stdin)")
+	threads := fs.Int("threads", convert.DefaultThreads, "threads for the imported test")
+	connections := fs.Int("connections", convert.DefaultConnections, "connections for the imported test")
+	duration := fs.String("duration", convert.DefaultDuration, "duration for the imported test")
+	force := fs.Bool("force", false, "overwrite existing tests with the same name")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: go-wrk import curl [flags] 'curl ...'")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var (
+		cfg      config.BenchmarkConfig
+		warnings []string
+		err      error
+	)
+	switch {
+	case *file != "":
+		var content []byte
+		if *file == "-" {
+			content, err = io.ReadAll(os.Stdin)
+		} else {
+			content, err = os.ReadFile(*file)
+		}
+		if err != nil {
+			return fmt.Errorf("reading curl command: %w", err)
+		}
+		cfg, warnings, err = convert.ParseCurl(string(content))
+	case fs.NArg() == 1:
+		cfg, warnings, err = convert.ParseCurl(fs.Arg(0))
+	case fs.NArg() > 1:
+		cfg, warnings, err = convert.ParseCurlArgs(fs.Args())
+	default:
+		fs.Usage()
+		return fmt.Errorf("no curl command given")
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+	if err != nil {
+		return err
+	}
+
+	cfg.Threads = *threads
+	cfg.Connections = *connections
+	cfg.Duration = *duration
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	testName := strings.TrimSpace(*name)
+	if testName == "" {
+		testName = convert.TestNameFromRequest(cfg.Method, cfg.TargetURL)
+	}
+	if err := saveImported(*collection, testName, cfg, *force); err != nil {
+		return err
+	}
+	fmt.Printf("Saved %s %s as test '%s' in collection '%s'.\n", cfg.Method, cfg.TargetURL,
+		config.SanitizeFilename(testName), config.SanitizeFilename(*collection))
+	return nil
+}
+
+func runImportOpenAPI(args []string) error {
+	fs := flag.NewFlagSet("import openapi", flag.ContinueOnError)
+	baseURL := fs.String("base-url", "", "scheme and host for requests, with a path to replace the server's (defaults to the spec's first server)")
+	collection := fs.String("collection", "", "collection name (defaults to the spec title)")
+	threads := fs.Int("threads", convert.DefaultThreads, "threads for each generated test")
+	connections := fs.Int("connections", convert.DefaultConnections, "connections for each generated test")
+	duration := fs.String("duration", convert.DefaultDuration, "duration for each generated test")
+	force := fs.Bool("force", false, "overwrite existing tests with the same name")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: go-wrk import openapi <spec.yaml|spec.json> [flags]")
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected exactly one specification file")
+	}
+
+	data, err := os.ReadFile(positional[0])
+	if err != nil {
+		return fmt.Errorf("reading specification: %w", err)
+	}
+	imported, err := convert.ParseOpenAPI(data, *baseURL)
+	if err != nil {
+		return err
+	}
+
+	collectionName := strings.TrimSpace(*collection)
+	if collectionName == "" {
+		collectionName = imported.Title
+	}
+	if strings.TrimSpace(collectionName) == "" {
+		collectionName = "openapi"
+	}
+
+	saved := 0
+	for _, test := range imported.Tests {
+		test.Config.Threads = *threads
+		test.Config.Connections = *connections
+		test.Config.Duration = *duration
+		if err := test.Config.Validate(); err != nil {
+			imported.Skipped = append(imported.Skipped, convert.SkippedOperation{
+				Method: test.Config.Method, Path: test.Name, Reason: err.Error(),
+			})
+			continue
+		}
+		if err := saveImported(collectionName, test.Name, test.Config, *force); err != nil {
+			return err
+		}
+		fmt.Printf("  + %-28s %-7s %s\n", test.Name, test.Config.Method, test.Config.TargetURL)
+		saved++
+	}
+
+	fmt.Printf("\nSaved %d test(s) to collection '%s'.\n", saved, config.SanitizeFilename(collectionName))
+	if len(imported.Skipped) > 0 {
+		fmt.Printf("Skipped %d operation(s):\n", len(imported.Skipped))
+		for _, skip := range imported.Skipped {
+			fmt.Printf("  - %-7s %s: %s\n", skip.Method, skip.Path, skip.Reason)
+		}
+	}
+	return nil
+}
+
+func runImportPostman(args []string) error {
+	fs := flag.NewFlagSet("import postman", flag.ContinueOnError)
+	envFile := fs.String("env", "", "Postman environment file used to resolve {{variables}}")
+	threads := fs.Int("threads", convert.DefaultThreads, "threads for each imported test")
+	connections := fs.Int("connections", convert.DefaultConnections, "connections for each imported test")
+	duration := fs.String("duration", convert.DefaultDuration, "duration for each imported test")
+	force := fs.Bool("force", false, "overwrite existing tests with the same name")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: go-wrk import postman <collection.json> [flags]")
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected exactly one Postman collection file")
+	}
+
+	collData, err := os.ReadFile(positional[0])
+	if err != nil {
+		return fmt.Errorf("reading Postman collection: %w", err)
+	}
+	var envData []byte
+	if *envFile != "" {
+		envData, err = os.ReadFile(*envFile)
+		if err != nil {
+			return fmt.Errorf("reading Postman environment: %w", err)
+		}
+	}
+	imported, err := convert.ParsePostman(collData, envData)
+	if err != nil {
+		return err
+	}
+	for _, w := range imported.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+
+	total := 0
+	for _, collection := range imported.Collections {
+		fmt.Printf("Collection '%s':\n", collection.Name)
+		for _, test := range collection.Tests {
+			test.Config.Threads = *threads
+			test.Config.Connections = *connections
+			test.Config.Duration = *duration
+			if err := saveImported(collection.Name, test.Name, test.Config, *force); err != nil {
+				return err
+			}
+			fmt.Printf("  + %-28s %-7s %s\n", test.Name, test.Config.Method, test.Config.TargetURL)
+			total++
+		}
+	}
+	fmt.Printf("\nSaved %d test(s) in %d collection(s).\n", total, len(imported.Collections))
+	return nil
+}
+
+func runImportHAR(args []string) error {
+	fs := flag.NewFlagSet("import har", flag.ContinueOnError)
+	mode := fs.String("mode", "mix", "mix: one test replaying all requests by frequency; collection: one test per request")
+	host := fs.String("host", "", "host to keep, e.g. api.example.com (defaults to the most requested host)")
+	collection := fs.String("collection", "har", "collection to save into")
+	name := fs.String("name", "recorded_mix", "test name in mix mode")
+	threads := fs.Int("threads", convert.DefaultThreads, "threads for the imported tests")
+	connections := fs.Int("connections", convert.DefaultConnections, "connections for the imported tests")
+	duration := fs.String("duration", convert.DefaultDuration, "duration for the imported tests")
+	force := fs.Bool("force", false, "overwrite existing tests with the same name")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: go-wrk import har <capture.har> [flags]")
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected exactly one HAR file")
+	}
+
+	data, err := os.ReadFile(positional[0])
+	if err != nil {
+		return fmt.Errorf("reading HAR file: %w", err)
+	}
+	imported, err := convert.ParseHAR(data, *host)
+	if err != nil {
+		return err
+	}
+	for _, w := range imported.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+
+	var tests []config.Test
+	switch *mode {
+	case "mix":
+		tests = []config.Test{{Name: *name, Config: imported.Mix()}}
+	case "collection":
+		tests = imported.Collection(*collection).Tests
+	default:
+		return fmt.Errorf("unknown mode %q; use mix or collection", *mode)
+	}
+
+	for _, test := range tests {
+		test.Config.Threads = *threads
+		test.Config.Connections = *connections
+		test.Config.Duration = *duration
+		if err := test.Config.Validate(); err != nil {
+			return fmt.Errorf("test %s: %w", test.Name, err)
+		}
+		if err := saveImported(*collection, test.Name, test.Config, *force); err != nil {
+			return err
+		}
+		if len(test.Config.Mix) > 0 {
+			fmt.Printf("  + %-28s mix of %d requests to %s\n", test.Name, len(test.Config.Mix), imported.Host)
+			for _, spec := range test.Config.Mix {
+				fmt.Printf("      weight %-4d %-7s %s\n", spec.Weight, spec.Method, spec.URL)
+			}
+		} else {
+			fmt.Printf("  + %-28s %-7s %s\n", test.Name, test.Config.Method, test.Config.TargetURL)
+		}
+	}
+	fmt.Printf("\nSaved %d test(s) to collection '%s'.\n", len(tests), config.SanitizeFilename(*collection))
+	return nil
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Th4phat/go-wrk/metrics"
+	"github.com/Th4phat/go-wrk/report"
+)
+
+func runReport(args []string) error {
+	flags := flag.NewFlagSet("report", flag.ContinueOnError)
+	output := flags.String("o", "", "HTML file to write (default: RESULT.html)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-wrk report [-o FILE] RESULT.json")
+		fmt.Fprintln(flags.Output(), "\nRenders a result file saved by 'go-wrk run -o' or by Ctrl+S in the TUI as a single")
+		fmt.Fprintln(flags.Output(), "HTML page that opens without network access.")
+		flags.PrintDefaults()
+	}
+	paths, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(paths) != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected one result file")
+	}
+	run, err := metrics.ReadRunFile(paths[0])
+	if err != nil {
+		return err
+	}
+
+	path := *output
+	if path == "" {
+		path = strings.TrimSuffix(paths[0], filepath.Ext(paths[0])) + ".html"
+	}
+	var buf bytes.Buffer
+	if err := report.Write(&buf, run); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Report written to %s\n", path)
+	return nil
+}
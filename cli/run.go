@@ -0,0 +1,293 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Th4phat/go-wrk/benchmark"
+	"github.com/Th4phat/go-wrk/config"
+	"github.com/Th4phat/go-wrk/metrics"
+	"github.com/Th4phat/go-wrk/report"
+)
+
+// runHeadless runs a single benchmark to completion without the TUI. The
+// optional onProgress callback receives every progress update, and a value
+// on the optional stop channel stops the benchmark early.
+func runHeadless(cfg config.BenchmarkConfig, onProgress func(metrics.ProgressUpdate), stop <-chan os.Signal) (metrics.BenchmarkResult, error) {
+	if err := cfg.Validate(); err != nil {
+		return metrics.BenchmarkResult{}, err
+	}
+
+	engine := benchmark.NewEngine()
+	progressChan := make(chan metrics.ProgressUpdate)
+	resultChan := make(chan metrics.BenchmarkResult)
+	if err := engine.Start(cfg, progressChan, resultChan); err != nil {
+		return metrics.BenchmarkResult{}, fmt.Errorf("starting benchmark: %w", err)
+	}
+
+	var result metrics.BenchmarkResult
+	gotResult := false
+	for progressChan != nil || resultChan != nil {
+		select {
+		case update, ok := <-progressChan:
+			if !ok {
+				progressChan = nil
+				continue
+			}
+			if onProgress != nil {
+				onProgress(update)
+			}
+		case res, ok := <-resultChan:
+			if !ok {
+				resultChan = nil
+				continue
+			}
+			result = res
+			gotResult = true
+		case <-stop:
+			engine.Stop()
+			stop = nil
+		}
+	}
+	engine.Wait()
+
+	if !gotResult {
+		return result, fmt.Errorf("benchmark finished without a result")
+	}
+	return result, nil
+}
+
+// runTest is a test selected to run headless.
+type runTest struct {
+	collection string
+	test       string
+	cfg        config.BenchmarkConfig
+}
+
+func (t runTest) name() string {
+	return t.collection + "/" + t.test
+}
+
+func runRun(args []string) error {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	env := flags.String("env", "", "environment to resolve variables with, as defined in collection files")
+	dir := flags.String("dir", config.GetConfigDir(), "directory holding the test collections")
+	output := flags.String("o", "", "write the results, with their per-second series, to this JSON file")
+	quiet := flags.Bool("q", false, "do not print progress while running")
+	junit := flags.String("junit", "", "write the results as JUnit XML to this file")
+	summary := flags.String("summary", os.Getenv("GITHUB_STEP_SUMMARY"), "append a Markdown summary to this file (default: $GITHUB_STEP_SUMMARY)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-wrk run [flags] collection[/test] | test-file ...")
+		fmt.Fprintln(flags.Output(), "\nRuns tests without the TUI: every test of a collection, a single test, or a")
+		fmt.Fprintln(flags.Output(), "JSON or YAML test file. Ctrl+C stops the current test and skips the rest.")
+		fmt.Fprintln(flags.Output(), "A test fails on a run error or a broken threshold.")
+		flags.PrintDefaults()
+	}
+	targets, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		flags.Usage()
+		return fmt.Errorf("no tests given")
+	}
+
+	tests, err := selectTests(targets, *dir, *env)
+	if err != nil {
+		return err
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	defer signal.Stop(stop)
+
+	run := metrics.RunFile{Environment: *env}
+	failed := 0
+	for i, test := range tests {
+		fmt.Fprintf(os.Stderr, "Running %s (%d/%d): %s %s\n", test.name(), i+1, len(tests), test.cfg.Method, test.cfg.TargetURL)
+		var onProgress func(metrics.ProgressUpdate)
+		if !*quiet {
+			start := time.Now()
+			onProgress = func(p metrics.ProgressUpdate) {
+				fmt.Fprintf(os.Stderr, "  [%3s] %-9s %8d completed %10.1f req/s  p99 %-8s %d errors\n",
+					time.Since(start).Round(time.Second), p.Phase, p.RequestsCompleted, p.CurrentThroughput,
+					p.LatencyP99.Round(time.Millisecond), p.Errors)
+			}
+		}
+		result, err := runHeadless(test.cfg, onProgress, stop)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", test.name(), err)
+			// Keep the test in the results so reports show it failed.
+			result = metrics.BenchmarkResult{Config: &test.cfg, Error: err}
+		} else {
+			printSummary(os.Stdout, test.name(), result)
+		}
+		run.Tests = append(run.Tests, metrics.TestResult{Collection: test.collection, Test: test.test, Result: result})
+		if !result.Passed() {
+			failed++
+		}
+		if len(stop) > 0 || errors.Is(result.Error, benchmark.ErrStopped) {
+			fmt.Fprintln(os.Stderr, "Stopped; skipping the remaining tests.")
+			break
+		}
+	}
+
+	if *output != "" {
+		if err := metrics.WriteRunFile(*output, run); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Results written to %s\n", *output)
+	}
+	if *junit != "" {
+		if err := writeOutput(*junit, false, func(w io.Writer) error { return report.WriteJUnit(w, run) }); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "JUnit results written to %s\n", *junit)
+	}
+	if *summary != "" {
+		if err := writeOutput(*summary, true, func(w io.Writer) error { return report.WriteMarkdown(w, run) }); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tests failed", failed, len(tests))
+	}
+	return nil
+}
+
+// writeOutput writes a results file, or appends to it.
+func writeOutput(path string, appendTo bool, write func(io.Writer) error) error {
+	mode := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if appendTo {
+		mode = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	f, err := os.OpenFile(path, mode, 0644)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// selectTests resolves run targets, in order, into tests with their
+// collection defaults applied and variables substituted.
+func selectTests(targets []string, dir, env string) ([]runTest, error) {
+	var collections []config.TestCollection
+	var tests []runTest
+	for _, target := range targets {
+		if info, err := os.Stat(target); err == nil && !info.IsDir() {
+			cfg, problems, _ := config.LoadTestFile(target)
+			if err := firstError(problems); err != nil {
+				return nil, err
+			}
+			collection, problems := config.CollectionForFile(target)
+			if err := firstError(problems); err != nil {
+				return nil, err
+			}
+			if cfg, err = collection.Resolve(cfg, env); err != nil {
+				return nil, fmt.Errorf("%s: %w", target, err)
+			}
+			name := strings.TrimSuffix(info.Name(), filepath.Ext(info.Name()))
+			tests = append(tests, runTest{collection: collection.Name, test: name, cfg: cfg})
+			continue
+		}
+
+		if collections == nil {
+			loaded, report, err := config.LoadTestCollections(dir)
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range report.Problems {
+				fmt.Fprintln(os.Stderr, p)
+			}
+			collections = loaded
+		}
+		collectionName, testName, _ := strings.Cut(target, "/")
+		var collection *config.TestCollection
+		for i := range collections {
+			if collections[i].Name == collectionName {
+				collection = &collections[i]
+			}
+		}
+		if collection == nil {
+			return nil, fmt.Errorf("no test file or collection named %q", target)
+		}
+		found := false
+		for _, test := range collection.Tests {
+			if testName != "" && test.Name != testName {
+				continue
+			}
+			cfg, err := collection.Resolve(test.Config, env)
+			if err != nil {
+				return nil, fmt.Errorf("%s/%s: %w", collection.Name, test.Name, err)
+			}
+			tests = append(tests, runTest{collection: collection.Name, test: test.Name, cfg: cfg})
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("collection %q has no test %q", collectionName, testName)
+		}
+	}
+	return tests, nil
+}
+
+// firstError returns the first problem that prevents a file from being used.
+func firstError(problems []config.Problem) error {
+	for _, p := range problems {
+		if !p.Warning {
+			return fmt.Errorf("%s", p)
+		}
+	}
+	return nil
+}
+
+// printSummary writes the main figures of a result.
+func printSummary(w io.Writer, name string, r metrics.BenchmarkResult) {
+	fmt.Fprintf(w, "%s\n", name)
+	fmt.Fprintf(w, "  Requests:    %d completed, %d errors (%.2f%%) in %s\n",
+		r.TotalRequestsCompleted, r.TotalErrors, r.ErrorRate, r.TotalDuration.Round(time.Millisecond))
+	fmt.Fprintf(w, "  Throughput:  %.2f req/sec\n", r.Throughput)
+	fmt.Fprintf(w, "  Latency:     avg %s  p50 %s  p95 %s  p99 %s\n",
+		r.LatencyAvg.Round(time.Microsecond), r.LatencyP50.Round(time.Microsecond),
+		r.LatencyP95.Round(time.Microsecond), r.LatencyP99.Round(time.Microsecond))
+	if r.Warmup != nil {
+		fmt.Fprintf(w, "  Warm-up:     %d completed, %.2f req/sec, p99 %s (excluded)\n",
+			r.Warmup.RequestsCompleted, r.Warmup.Throughput, r.Warmup.LatencyP99.Round(time.Microsecond))
+	}
+	if r.Cooldown != nil {
+		fmt.Fprintf(w, "  Cool-down:   %d completed, %.2f req/sec, p99 %s (excluded)\n",
+			r.Cooldown.RequestsCompleted, r.Cooldown.Throughput, r.Cooldown.LatencyP99.Round(time.Microsecond))
+	}
+	if s := r.Search; s != nil {
+		fmt.Fprintf(w, "  Sustainable: %.1f req/s after %d steps\n", s.SustainableRate, len(s.Steps))
+	}
+	errKeys := make([]string, 0, len(r.ErrorDetails))
+	for k := range r.ErrorDetails {
+		errKeys = append(errKeys, k)
+	}
+	sort.Strings(errKeys)
+	for _, k := range errKeys {
+		fmt.Fprintf(w, "  %s: %d\n", k, r.ErrorDetails[k])
+	}
+	for _, check := range r.ThresholdChecks() {
+		status := "pass"
+		if !check.Passed {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "  Threshold:   %s <= %s: %s (%s)\n", check.Metric, check.Limit, check.Actual, status)
+	}
+	if r.Error != nil {
+		fmt.Fprintf(w, "  Error: %v\n", r.Error)
+	}
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Th4phat/go-wrk/secret"
+)
+
+func runSecrets(args []string) error {
+	flags := flag.NewFlagSet("secrets", flag.ContinueOnError)
+	storePath := flags.String("store", secret.StorePath(), "path of the encrypted secrets store")
+	passphraseFile := flags.String("passphrase-file", "", "read the passphrase from a file instead of $"+secret.PassphraseEnv)
+	flags.Usage = func() {
+		out := flags.Output()
+		fmt.Fprintln(out, "Usage: go-wrk secrets <list | set NAME | rm NAME> [flags]")
+		fmt.Fprintln(out, "\nManages the encrypted store read by ${secret:NAME} references. The value")
+		fmt.Fprintln(out, "for 'set' is read from standard input, e.g. echo \"$TOKEN\" | go-wrk secrets set api_token")
+		fmt.Fprintf(out, "The passphrase comes from $%s unless -passphrase-file is given.\n\n", secret.PassphraseEnv)
+		flags.PrintDefaults()
+	}
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		flags.Usage()
+		return fmt.Errorf("no secrets subcommand given")
+	}
+
+	passphrase := os.Getenv(secret.PassphraseEnv)
+	if *passphraseFile != "" {
+		data, err := os.ReadFile(*passphraseFile)
+		if err != nil {
+			return fmt.Errorf("reading passphrase: %w", err)
+		}
+		passphrase = strings.TrimRight(string(data), "\r\n")
+	}
+	store, err := secret.OpenStore(*storePath, passphrase)
+	if err != nil {
+		return err
+	}
+
+	switch sub, rest := positional[0], positional[1:]; sub {
+	case "list":
+		for _, name := range store.Names() {
+			fmt.Println(name)
+		}
+		return nil
+
+	case "set":
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: go-wrk secrets set NAME")
+		}
+		if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			fmt.Fprintf(os.Stderr, "Value for %s (input is visible; end with Enter): ", rest[0])
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && err != io.EOF {
+				return fmt.Errorf("reading secret value: %w", err)
+			}
+			store.Set(rest[0], strings.TrimRight(line, "\r\n"))
+		} else {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("reading secret value: %w", err)
+			}
+			store.Set(rest[0], strings.TrimRight(string(data), "\r\n"))
+		}
+		if err := store.Save(); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Stored secret '%s'. Reference it as ${secret:%s}.\n", rest[0], rest[0])
+		return nil
+
+	case "rm":
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: go-wrk secrets rm NAME")
+		}
+		if !store.Delete(rest[0]) {
+			return fmt.Errorf("no secret named %q", rest[0])
+		}
+		if err := store.Save(); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Removed secret '%s'.\n", rest[0])
+		return nil
+	}
+	return fmt.Errorf("unknown secrets subcommand %q", positional[0])
+}
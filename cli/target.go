@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+
+	"github.com/Th4phat/go-wrk/config"
+	"github.com/Th4phat/go-wrk/metrics"
+	"github.com/Th4phat/go-wrk/target"
+)
+
+func targetFlags(fs *flag.FlagSet, opts *target.Options) *string {
+	fs.DurationVar(&opts.Latency, "latency", 0, "base artificial latency per response")
+	fs.DurationVar(&opts.Jitter, "jitter", 0, "latency spread (uniform range or normal std deviation)")
+	fs.StringVar(&opts.Distribution, "dist", target.DistFixed, "latency distribution: fixed, uniform, normal, exponential")
+	fs.IntVar(&opts.ResponseSize, "size", 0, "response body size in bytes")
+	fs.Float64Var(&opts.ErrorPercent, "errors", 0, "percentage of requests answered with HTTP 500")
+	return fs.String("status", "", "status code mix for non-error responses, e.g. 200:90,404:10")
+}
+
+func runTarget(args []string) error {
+	fs := flag.NewFlagSet("target", flag.ContinueOnError)
+	var opts target.Options
+	fs.StringVar(&opts.Addr, "addr", "127.0.0.1:8080", "address to listen on")
+	statusMix := targetFlags(fs, &opts)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	mix, err := target.ParseStatusMix(*statusMix)
+	if err != nil {
+		return err
+	}
+	opts.StatusMix = mix
+
+	srv, err := target.New(opts)
+	if err != nil {
+		return err
+	}
+	if err := srv.Start(); err != nil {
+		return err
+	}
+	fmt.Printf("Target server listening on %s (latency %s %s, jitter %s, size %dB, errors %.1f%%)\n",
+		srv.URL(), opts.Distribution, opts.Latency, opts.Jitter, opts.ResponseSize, opts.ErrorPercent)
+	fmt.Println("Press Ctrl+C to stop.")
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt)
+	<-sigChan
+	fmt.Println("\nShutting down target server.")
+	return srv.Close()
+}
+
+func runCalibrate(args []string) error {
+	fs := flag.NewFlagSet("calibrate", flag.ContinueOnError)
+	var opts target.Options
+	statusMix := targetFlags(fs, &opts)
+	threads := fs.Int("threads", 10, "number of threads, as in the benchmark configuration")
+	connections := fs.Int("connections", 50, "number of connections, as in the benchmark configuration")
+	duration := fs.String("duration", "5s", "duration of each calibration round")
+	rounds := fs.Int("rounds", 3, "number of calibration rounds; the best one is reported")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *rounds <= 0 {
+		return fmt.Errorf("rounds must be greater than 0")
+	}
+
+	mix, err := target.ParseStatusMix(*statusMix)
+	if err != nil {
+		return err
+	}
+	opts.StatusMix = mix
+	opts.Addr = "127.0.0.1:0"
+
+	srv, err := target.New(opts)
+	if err != nil {
+		return err
+	}
+	if err := srv.Start(); err != nil {
+		return err
+	}
+	defer srv.Close()
+
+	cfg := config.BenchmarkConfig{
+		TargetURL:   srv.URL(),
+		Method:      "GET",
+		Threads:     *threads,
+		Connections: *connections,
+		Duration:    *duration,
+	}
+	fmt.Printf("Calibrating against local target %s (%d threads, %d connections, %s x %d rounds)\n",
+		cfg.TargetURL, cfg.Threads, cfg.Connections, cfg.Duration, *rounds)
+
+	var best metrics.BenchmarkResult
+	for i := 1; i <= *rounds; i++ {
+		res, err := runHeadless(cfg, nil, nil)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  round %d: %10.2f req/sec  p50 %-10s p99 %-10s errors %d\n", i,
+			res.Throughput, res.LatencyP50.Round(time.Microsecond), res.LatencyP99.Round(time.Microsecond), res.TotalErrors)
+		errKeys := make([]string, 0, len(res.ErrorDetails))
+		for k := range res.ErrorDetails {
+			errKeys = append(errKeys, k)
+		}
+		sort.Strings(errKeys)
+		for _, k := range errKeys {
+			fmt.Printf("    %s: %d\n", k, res.ErrorDetails[k])
+		}
+		if res.Throughput > best.Throughput {
+			best = res
+		}
+	}
+
+	fmt.Printf("\nMax RPS this machine can generate with these settings: %.2f req/sec\n", best.Throughput)
+	fmt.Println("Benchmarks of real services approaching this rate are limited by the client, not the service.")
+	return nil
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Th4phat/go-wrk/config"
+)
+
+func runValidate(args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	migrate := flags.Bool("migrate", false, "rewrite files using an older schema version in the current one")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-wrk validate [flags] [file or directory...]")
+		fmt.Fprintln(flags.Output(), "\nChecks test and collection files (JSON or YAML). Defaults to the config directory.")
+		flags.PrintDefaults()
+	}
+	paths, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		paths = []string{config.GetConfigDir()}
+	}
+
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(filepath.Ext(p)) {
+			case ".json", ".yaml", ".yml":
+				if !d.IsDir() {
+					files = append(files, p)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	failed, migrated := 0, 0
+	for _, file := range files {
+		if config.IsCollectionFile(file) {
+			_, problems := config.LoadCollectionFile(file)
+			for _, p := range problems {
+				fmt.Println(p)
+			}
+			if config.HasErrors(problems) {
+				failed++
+			}
+			continue
+		}
+		_, problems, migratedFrom := config.LoadTestFile(file)
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+		if config.HasErrors(problems) {
+			failed++
+			continue
+		}
+		if migratedFrom == 0 {
+			continue
+		}
+		if !*migrate {
+			fmt.Printf("%s: uses schema version %d; run with -migrate to upgrade it to %d\n", file, migratedFrom, config.SchemaVersion)
+			continue
+		}
+		if _, err := config.MigrateTestFile(file); err != nil {
+			return err
+		}
+		fmt.Printf("%s: migrated from schema version %d to %d\n", file, migratedFrom, config.SchemaVersion)
+		migrated++
+	}
+
+	fmt.Printf("\nChecked %d file(s): %d valid, %d with errors", len(files), len(files)-failed, failed)
+	if migrated > 0 {
+		fmt.Printf(", %d migrated", migrated)
+	}
+	fmt.Println(".")
+	if failed > 0 {
+		return fmt.Errorf("%d file(s) failed validation", failed)
+	}
+	return nil
+}
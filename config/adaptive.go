@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Controllers of adaptive concurrency.
+const (
+	ControllerAIMD = "aimd"
+	ControllerPID  = "pid"
+)
+
+// Defaults of adaptive concurrency.
+const (
+	DefaultAdaptiveInterval = time.Second
+	DefaultAIMDIncrease     = 1
+	DefaultAIMDDecrease     = 0.75
+	DefaultPIDKp            = 0.3
+	DefaultPIDKi            = 0.5
+)
+
+// AdaptiveConcurrency varies the number of active workers to hold a latency
+// or error rate goal. Where the concurrency settles is the most the target
+// handles within the goal.
+type AdaptiveConcurrency struct {
+	Controller string `json:"controller,omitempty"` // aimd (default) or pid
+	TargetP95  string `json:"target_p95,omitempty"` // Latency goal, such as "200ms"
+	// TargetErrorRate is the error rate goal, in percent. AIMD backs off when
+	// either goal is missed; PID follows the latency goal if both are set.
+	TargetErrorRate *float64 `json:"target_error_rate,omitempty"`
+	Min             int      `json:"min,omitempty"`      // Fewest workers; defaults to 1
+	Max             int      `json:"max,omitempty"`      // Most workers; defaults to Connections
+	Initial         int      `json:"initial,omitempty"`  // Workers at the start; defaults to Min
+	Interval        string   `json:"interval,omitempty"` // How often the controller decides; defaults to 1s
+	// AIMD adds Increase workers while the goal is met, and multiplies the
+	// workers by Decrease when it is missed.
+	Increase int     `json:"increase,omitempty"`
+	Decrease float64 `json:"decrease,omitempty"`
+	// PID gains, applied to the relative distance from the goal.
+	Kp float64 `json:"kp,omitempty"`
+	Ki float64 `json:"ki,omitempty"`
+	Kd float64 `json:"kd,omitempty"`
+}
+
+// AdaptiveParams holds adaptive concurrency settings with defaults applied.
+type AdaptiveParams struct {
+	Controller         string
+	TargetP95          time.Duration // 0 if unset
+	TargetErrorRate    float64
+	HasTargetErrorRate bool
+	Min, Max, Initial  int
+	Interval           time.Duration
+	Increase           int
+	Decrease           float64
+	Kp, Ki, Kd         float64
+}
+
+// Params returns the settings with their defaults; Max defaults to
+// connections. Invalid values are left for check to report.
+func (a AdaptiveConcurrency) Params(connections int) AdaptiveParams {
+	p := AdaptiveParams{
+		Controller: strings.ToLower(a.Controller),
+		Min:        a.Min, Max: a.Max, Initial: a.Initial,
+		Interval: DefaultAdaptiveInterval,
+		Increase: a.Increase, Decrease: a.Decrease,
+		Kp: a.Kp, Ki: a.Ki, Kd: a.Kd,
+	}
+	if p.Controller == "" {
+		p.Controller = ControllerAIMD
+	}
+	p.TargetP95, _ = time.ParseDuration(a.TargetP95)
+	if a.TargetErrorRate != nil {
+		p.TargetErrorRate, p.HasTargetErrorRate = *a.TargetErrorRate, true
+	}
+	if p.Min == 0 {
+		p.Min = 1
+	}
+	if p.Max == 0 {
+		p.Max = connections
+	}
+	if p.Initial == 0 {
+		p.Initial = p.Min
+	}
+	if d, err := time.ParseDuration(a.Interval); err == nil && d > 0 {
+		p.Interval = d
+	}
+	if p.Increase == 0 {
+		p.Increase = DefaultAIMDIncrease
+	}
+	if p.Decrease == 0 {
+		p.Decrease = DefaultAIMDDecrease
+	}
+	if p.Kp == 0 && p.Ki == 0 && p.Kd == 0 {
+		p.Kp, p.Ki = DefaultPIDKp, DefaultPIDKi
+	}
+	return p
+}
+
+// String describes the goal, such as "aimd holding p95 < 200ms".
+func (a AdaptiveConcurrency) String() string {
+	p := a.Params(0)
+	var goals []string
+	if p.TargetP95 > 0 {
+		goals = append(goals, fmt.Sprintf("p95 < %s", p.TargetP95))
+	}
+	if p.HasTargetErrorRate {
+		goals = append(goals, fmt.Sprintf("errors <= %g%%", p.TargetErrorRate))
+	}
+	return fmt.Sprintf("%s holding %s", p.Controller, strings.Join(goals, " and "))
+}
+
+// check validates the settings, reporting problems under the "adaptive"
+// field.
+func (a AdaptiveConcurrency) check(connections int) []FieldError {
+	var problems []FieldError
+	add := func(field string, format string, args ...any) {
+		problems = append(problems, FieldError{Field: "adaptive." + field, Err: fmt.Errorf(format, args...)})
+	}
+	p := a.Params(connections)
+	if p.Controller != ControllerAIMD && p.Controller != ControllerPID {
+		add("controller", "unknown controller %q (use %s or %s)", a.Controller, ControllerAIMD, ControllerPID)
+	}
+	if a.TargetP95 != "" && p.TargetP95 <= 0 {
+		add("target_p95", "invalid latency goal %q", a.TargetP95)
+	}
+	if a.TargetP95 == "" && a.TargetErrorRate == nil {
+		add("target_p95", "adaptive concurrency needs target_p95 or target_error_rate")
+	}
+	if p.HasTargetErrorRate && (p.TargetErrorRate < 0 || p.TargetErrorRate > 100) {
+		add("target_error_rate", "error rate goal must be between 0 and 100")
+	}
+	if p.Controller == ControllerPID && a.TargetP95 == "" && p.HasTargetErrorRate && p.TargetErrorRate == 0 {
+		add("target_error_rate", "the pid controller needs an error rate goal greater than 0")
+	}
+	if a.Min < 0 || a.Max < 0 || a.Initial < 0 {
+		add("min", "worker counts must not be negative")
+	} else if p.Max > 0 && (p.Min > p.Max || p.Initial < p.Min || p.Initial > p.Max) {
+		add("initial", "workers must satisfy min <= initial <= max (got %d, %d, %d)", p.Min, p.Initial, p.Max)
+	}
+	if a.Interval != "" {
+		if d, err := time.ParseDuration(a.Interval); err != nil || d <= 0 {
+			add("interval", "invalid interval %q", a.Interval)
+		}
+	}
+	if a.Increase < 0 {
+		add("increase", "increase must not be negative")
+	}
+	if a.Decrease < 0 || a.Decrease >= 1 {
+		add("decrease", "decrease must be between 0 and 1")
+	}
+	return problems
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Arrival processes of the open model.
+const (
+	ArrivalPoisson  = "poisson"
+	ArrivalConstant = "constant"
+	ArrivalTrace    = "trace"
+)
+
+// What to do with an arrival when MaxInFlight requests are outstanding.
+const (
+	OnFullDrop  = "drop"
+	OnFullQueue = "queue"
+)
+
+// ArrivalModel runs the benchmark as an open model: requests are started on a
+// schedule, whether or not earlier ones have completed, so a slow target
+// builds up a queue instead of slowing the load down.
+type ArrivalModel struct {
+	Process string  `json:"process,omitempty"` // One of the Arrival* constants; defaults to poisson
+	Rate    float64 `json:"rate,omitempty"`    // Mean arrivals per second
+	// Trace is a file of inter-arrival gaps, one per line, as durations such
+	// as "15ms" or as seconds. It is replayed in a loop, scaled to Rate if set.
+	Trace string `json:"trace,omitempty"`
+	// MaxInFlight caps outstanding requests; it defaults to Connections.
+	MaxInFlight int    `json:"max_in_flight,omitempty"`
+	OnFull      string `json:"on_full,omitempty"` // drop (default) or queue
+}
+
+// ProcessName returns the arrival process, defaulting to poisson.
+func (a ArrivalModel) ProcessName() string {
+	if a.Process == "" {
+		return ArrivalPoisson
+	}
+	return strings.ToLower(a.Process)
+}
+
+// String describes the arrival model, such as "poisson at 200 req/s".
+func (a ArrivalModel) String() string {
+	s := a.ProcessName()
+	if a.ProcessName() == ArrivalTrace {
+		s += " " + a.Trace
+	}
+	if a.Rate > 0 {
+		s += fmt.Sprintf(" at %g req/s", a.Rate)
+	}
+	return s
+}
+
+// check validates the arrival model, reporting problems under the "arrivals"
+// field.
+func (a ArrivalModel) check() []FieldError {
+	var problems []FieldError
+	add := func(field string, format string, args ...any) {
+		problems = append(problems, FieldError{Field: "arrivals." + field, Err: fmt.Errorf(format, args...)})
+	}
+	switch a.ProcessName() {
+	case ArrivalPoisson, ArrivalConstant:
+		if a.Rate <= 0 {
+			add("rate", "%s arrivals need a rate greater than 0", a.ProcessName())
+		}
+		if a.Trace != "" {
+			add("trace", "a trace needs process %s", ArrivalTrace)
+		}
+	case ArrivalTrace:
+		if a.Trace == "" {
+			add("trace", "trace arrivals need a trace file")
+		}
+		if a.Rate < 0 {
+			add("rate", "rate must not be negative")
+		}
+	default:
+		add("process", "unknown arrival process %q (use %s, %s or %s)", a.Process, ArrivalPoisson, ArrivalConstant, ArrivalTrace)
+	}
+	if a.MaxInFlight < 0 {
+		add("max_in_flight", "max_in_flight must not be negative")
+	}
+	if a.OnFull != "" && !strings.EqualFold(a.OnFull, OnFullDrop) && !strings.EqualFold(a.OnFull, OnFullQueue) {
+		add("on_full", "unknown on_full %q (use %s or %s)", a.OnFull, OnFullDrop, OnFullQueue)
+	}
+	return problems
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Body types of a request payload. They choose the Content-Type sent when
+// the test sets none.
+const (
+	BodyJSON      = "json"
+	BodyForm      = "form"
+	BodyXML       = "xml"
+	BodyText      = "text"
+	BodyBinary    = "binary"    // Raw bytes of PayloadFile
+	BodyMultipart = "multipart" // Form fields and files as multipart/form-data
+)
+
+// BodyTypes lists the body types in the order the TUI offers them.
+var BodyTypes = []string{BodyJSON, BodyForm, BodyMultipart, BodyXML, BodyText, BodyBinary}
+
+var bodyContentTypes = map[string]string{
+	BodyJSON:      "application/json",
+	BodyForm:      "application/x-www-form-urlencoded",
+	BodyXML:       "application/xml",
+	BodyText:      "text/plain; charset=utf-8",
+	BodyBinary:    "application/octet-stream",
+	BodyMultipart: "multipart/form-data", // The boundary is added when the body is encoded
+}
+
+// BodyContentType returns the Content-Type for a body type. An empty body
+// type is JSON, as it was before body types existed.
+func BodyContentType(bodyType string) string {
+	if bodyType == "" {
+		bodyType = BodyJSON
+	}
+	return bodyContentTypes[strings.ToLower(bodyType)]
+}
+
+// IsFormBody reports whether a body type is encoded from form fields.
+func IsFormBody(bodyType string) bool {
+	return strings.EqualFold(bodyType, BodyForm) || strings.EqualFold(bodyType, BodyMultipart)
+}
+
+// FormField is a field of a form or multipart body. Multipart fields may send
+// a file from disk instead of a value.
+type FormField struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	File        string `json:"file,omitempty"`         // Path of a file to upload (multipart only)
+	ContentType string `json:"content_type,omitempty"` // Content-Type of the part (multipart only)
+	Filename    string `json:"filename,omitempty"`     // File name sent for File; defaults to its base name
+}
+
+// ParseFormField parses a field in curl's -F syntax: "name=value", or
+// "name=@path" with optional ";type=content/type" and ";filename=name".
+func ParseFormField(spec string) (FormField, error) {
+	name, value, found := strings.Cut(spec, "=")
+	name = strings.TrimSpace(name)
+	if !found || name == "" {
+		return FormField{}, fmt.Errorf("form field %q must be name=value", spec)
+	}
+	field := FormField{Name: name}
+	if !strings.HasPrefix(value, "@") {
+		field.Value = value
+		return field, nil
+	}
+	params := strings.Split(value[1:], ";")
+	field.File = params[0]
+	for _, param := range params[1:] {
+		key, val, _ := strings.Cut(param, "=")
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "type":
+			field.ContentType = val
+		case "filename":
+			field.Filename = val
+		default:
+			return FormField{}, fmt.Errorf("form field %q: unknown parameter %q", name, key)
+		}
+	}
+	if field.File == "" {
+		return FormField{}, fmt.Errorf("form field %q: file path cannot be empty", name)
+	}
+	return field, nil
+}
+
+// String formats the field in the syntax ParseFormField accepts.
+func (f FormField) String() string {
+	if f.File == "" {
+		return f.Name + "=" + f.Value
+	}
+	s := f.Name + "=@" + f.File
+	if f.ContentType != "" {
+		s += ";type=" + f.ContentType
+	}
+	if f.Filename != "" {
+		s += ";filename=" + f.Filename
+	}
+	return s
+}
+
+// HasBody reports whether the config sends a request body.
+func (c BenchmarkConfig) HasBody() bool {
+	return c.Payload != "" || c.PayloadFile != "" || len(c.Form) > 0
+}
+
+// CheckFiles reports the first payload, form or trace file of the config
+// that cannot be read. The files are read only when a run starts, so a file
+// removed after the test was written is otherwise found late.
+func (c BenchmarkConfig) CheckFiles() error {
+	readable := func(what, path string) error {
+		if path == "" || HasReferences(path) {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", what, err)
+		}
+		return f.Close()
+	}
+	if err := readable("payload file", c.PayloadFile); err != nil {
+		return err
+	}
+	if strings.EqualFold(c.BodyType, BodyMultipart) {
+		for _, f := range c.Form {
+			if err := readable(fmt.Sprintf("file of form field %q", f.Name), f.File); err != nil {
+				return err
+			}
+		}
+	}
+	if c.Arrivals != nil && c.Arrivals.ProcessName() == ArrivalTrace {
+		return readable("arrival trace", c.Arrivals.Trace)
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// collectionFileBase is the name, without extension, of the optional settings
+// file in a collection directory. Tests cannot use this name.
+const collectionFileBase = "collection"
+
+// variablePattern matches ${name} references. Prefixed references such as
+// ${env:NAME} are secrets, resolved by the engine; referencePattern matches both.
+var (
+	variablePattern  = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_.-]*)\}`)
+	referencePattern = regexp.MustCompile(`\$\{[^{}]+\}`)
+)
+
+// CollectionSettings is the content of a collection's collection.json (or
+// .yaml) file.
+type CollectionSettings struct {
+	Version int `json:"version,omitempty"`
+
+	// Defaults fills every field a test leaves unset. Default headers are
+	// merged under the test's own headers.
+	Defaults BenchmarkConfig `json:"defaults"`
+
+	// Variables are substituted for ${name} in URLs, headers and payloads.
+	// The selected environment's variables take precedence.
+	Variables    map[string]string            `json:"variables,omitempty"`
+	Environments map[string]map[string]string `json:"environments,omitempty"`
+}
+
+// isCollectionFile reports whether fileName is a collection settings file.
+func isCollectionFile(fileName string) bool {
+	name, ok := testFileName(fileName)
+	return ok && name == collectionFileBase
+}
+
+// IsCollectionFile reports whether path names a collection settings file
+// rather than a test.
+func IsCollectionFile(path string) bool {
+	return isCollectionFile(filepath.Base(path))
+}
+
+// findCollectionFile returns the settings file of the collection directory
+// dir, or "" if it has none.
+func findCollectionFile(dir string) string {
+	for _, ext := range []string{".json", ".yaml", ".yml"} {
+		if p := filepath.Join(dir, collectionFileBase+ext); fileExists(p) {
+			return p
+		}
+	}
+	return ""
+}
+
+// LoadCollectionFile reads and checks a collection settings file.
+func LoadCollectionFile(path string) (CollectionSettings, []Problem) {
+	var settings CollectionSettings
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return settings, []Problem{{File: path, Message: fmt.Sprintf("reading collection file: %v", err)}}
+	}
+	raw, root, problem := parseDocument(path, data)
+	if problem != nil {
+		return settings, []Problem{*problem}
+	}
+	at := func(field, message string, warning bool) Problem {
+		line, col := locate(root, field)
+		return Problem{File: path, Line: line, Column: col, Field: field, Message: message, Warning: warning}
+	}
+
+	if v, ok := raw["version"]; ok && v != nil {
+		if n, ok := asInt(v); !ok || n < 1 || n > SchemaVersion {
+			return settings, []Problem{at("version", fmt.Sprintf("unsupported schema version %v", v), false)}
+		}
+	}
+	problems := decodeFields(raw, reflect.ValueOf(&settings).Elem(), "", at)
+	if len(settings.Defaults.Mix) > 0 {
+		problems = append(problems, at("defaults.mix", "a request mix cannot be a default", false))
+	}
+	for name := range settings.Environments {
+		if strings.TrimSpace(name) == "" {
+			problems = append(problems, at("environments", "environment names cannot be empty", false))
+		}
+	}
+	return settings, problems
+}
+
+// applyDefaults fills the fields cfg leaves unset from defaults.
+func applyDefaults(cfg BenchmarkConfig, defaults BenchmarkConfig) BenchmarkConfig {
+	if cfg.TargetURL == "" {
+		cfg.TargetURL = defaults.TargetURL
+	}
+	if cfg.Method == "" {
+		cfg.Method = defaults.Method
+	}
+	if !cfg.HasBody() {
+		cfg.Payload = defaults.Payload
+		cfg.PayloadFile = defaults.PayloadFile
+		cfg.Form = defaults.Form
+	}
+	if cfg.BodyType == "" {
+		cfg.BodyType = defaults.BodyType
+	}
+	if cfg.Insecure == nil {
+		cfg.Insecure = defaults.Insecure
+	}
+	if cfg.Threads == 0 {
+		cfg.Threads = defaults.Threads
+	}
+	if cfg.Connections == 0 {
+		cfg.Connections = defaults.Connections
+	}
+	if cfg.Duration == "" {
+		cfg.Duration = defaults.Duration
+	}
+	if cfg.Warmup == "" {
+		cfg.Warmup = defaults.Warmup
+	}
+	if cfg.Cooldown == "" {
+		cfg.Cooldown = defaults.Cooldown
+	}
+	if cfg.Thresholds == nil {
+		cfg.Thresholds = defaults.Thresholds
+	}
+	if cfg.Driver == "" {
+		cfg.Driver = defaults.Driver
+	}
+	// The load model is taken whole: a test that sets any part of it keeps
+	// its own, so a default search cannot combine with a test's users.
+	if cfg.Users == nil && cfg.Arrivals == nil && cfg.Search == nil && cfg.Adaptive == nil {
+		cfg.Users = defaults.Users
+		cfg.Arrivals = defaults.Arrivals
+		cfg.Search = defaults.Search
+		cfg.Adaptive = defaults.Adaptive
+	}
+	if len(defaults.Headers) > 0 {
+		headers := make(map[string]string, len(defaults.Headers)+len(cfg.Headers))
+		for k, v := range defaults.Headers {
+			headers[k] = v
+		}
+		for k, v := range cfg.Headers {
+			// Header names are case-insensitive; the test's spelling wins.
+			for dk := range headers {
+				if strings.EqualFold(dk, k) {
+					delete(headers, dk)
+				}
+			}
+			headers[k] = v
+		}
+		cfg.Headers = headers
+	}
+	return cfg
+}
+
+// EnvironmentNames returns the sorted names of the collection's environments.
+func (c TestCollection) EnvironmentNames() []string {
+	names := make([]string, 0, len(c.Environments))
+	for name := range c.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyDefaults returns cfg with the fields it leaves unset filled from the
+// collection's defaults.
+func (c TestCollection) ApplyDefaults(cfg BenchmarkConfig) BenchmarkConfig {
+	return applyDefaults(cfg, c.Defaults)
+}
+
+// Resolve applies the collection's defaults to cfg and substitutes its
+// variables, with those of the named environment taking precedence. An empty
+// env uses only the collection variables. It fails on an unknown environment
+// or an undefined variable.
+func (c TestCollection) Resolve(cfg BenchmarkConfig, env string) (BenchmarkConfig, error) {
+	cfg = c.ApplyDefaults(cfg)
+	vars := make(map[string]string, len(c.Variables))
+	for k, v := range c.Variables {
+		vars[k] = v
+	}
+	if env != "" {
+		envVars, ok := c.Environments[env]
+		if !ok {
+			if len(c.Environments) == 0 {
+				return cfg, fmt.Errorf("collection '%s' defines no environments", c.Name)
+			}
+			return cfg, fmt.Errorf("collection '%s' has no environment %q (available: %s)",
+				c.Name, env, strings.Join(c.EnvironmentNames(), ", "))
+		}
+		for k, v := range envVars {
+			vars[k] = v
+		}
+	}
+	return ExpandVariables(cfg, vars)
+}
+
+// ExpandVariables replaces ${name} references in cfg's URLs, headers and
+// payloads with values from vars. It fails if a reference is undefined.
+func ExpandVariables(cfg BenchmarkConfig, vars map[string]string) (BenchmarkConfig, error) {
+	var undefined []string
+	expand := func(s string) string {
+		return variablePattern.ReplaceAllStringFunc(s, func(ref string) string {
+			name := ref[2 : len(ref)-1]
+			if v, ok := vars[name]; ok {
+				return v
+			}
+			undefined = append(undefined, name)
+			return ref
+		})
+	}
+	cfg = cfg.MapValues(expand)
+
+	if len(undefined) > 0 {
+		sort.Strings(undefined)
+		return cfg, fmt.Errorf("undefined variable(s): %s", strings.Join(uniqueStrings(undefined), ", "))
+	}
+	return cfg, nil
+}
+
+// HasReferences reports whether s contains a ${...} variable or secret
+// reference, which can only be checked once resolved.
+func HasReferences(s string) bool {
+	return referencePattern.MatchString(s)
+}
+
+func uniqueStrings(sorted []string) []string {
+	out := sorted[:0]
+	for i, s := range sorted {
+		if i == 0 || s != sorted[i-1] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -1,13 +1,13 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
+	"unicode"
 )
 
 type Test struct {
@@ -18,62 +18,353 @@ type Test struct {
 type TestCollection struct {
 	Name  string
 	Tests []Test
+
+	// Defaults, Variables and Environments come from the collection file;
+	// see CollectionSettings. Tests are kept as written, without the defaults
+	// or with their ${name} references, until Resolve.
+	Defaults     BenchmarkConfig
+	Variables    map[string]string
+	Environments map[string]map[string]string
+}
+
+// RequestSpec is one request of a weighted request mix. Headers are added to
+// the config's common headers.
+type RequestSpec struct {
+	Weight  int               `json:"weight"`
+	Method  string            `json:"method,omitempty"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Payload string            `json:"payload,omitempty"`
 }
 
 type BenchmarkConfig struct {
-	TargetURL   string `json:"url"`
-	Method      string `json:"method,omitempty"`
-	Payload     string `json:"payload,omitempty"`
-	Threads     int    `json:"threads"`
-	Connections int    `json:"connections"`
-	Duration    string `json:"duration"`
+	// Version is the schema version of the file the config was loaded from.
+	// Older files are migrated on load; see SchemaVersion.
+	Version int `json:"version,omitempty"`
+
+	TargetURL   string            `json:"url"`
+	Method      string            `json:"method,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Payload     string            `json:"payload,omitempty"`
+	BodyType    string            `json:"body_type,omitempty"`    // One of BodyTypes; empty means JSON
+	PayloadFile string            `json:"payload_file,omitempty"` // Read as the body when the run starts
+	Form        []FormField       `json:"form,omitempty"`         // Fields of a form or multipart body
+	Insecure    *bool             `json:"insecure,omitempty"`     // Skip TLS certificate verification; nil leaves it to the defaults
+	Threads     int               `json:"threads"`
+	Connections int               `json:"connections"`
+	Duration    string            `json:"duration"`
+
+	// Warmup and Cooldown add windows before and after Duration in which load
+	// is sent but samples are kept apart from the measured statistics.
+	Warmup   string `json:"warmup,omitempty"`
+	Cooldown string `json:"cooldown,omitempty"`
+
+	// Mix, when set, replaces the single request with a weighted mix. Every
+	// request must target the same scheme and host as TargetURL.
+	Mix []RequestSpec `json:"mix,omitempty"`
+
+	// Users, when set, replaces the saturating worker loops with virtual
+	// users that think or pace between requests.
+	Users *UserModel `json:"users,omitempty"`
+
+	// Arrivals, when set, starts requests on a schedule (an open model)
+	// instead of as fast as the workers can send them.
+	Arrivals *ArrivalModel `json:"arrivals,omitempty"`
+
+	// Search, when set, runs a capacity search instead of a single run. Its
+	// steps use Arrivals for everything but the rate.
+	Search *CapacitySearch `json:"search,omitempty"`
+
+	// Adaptive, when set, varies the number of workers to hold a latency or
+	// error rate goal.
+	Adaptive *AdaptiveConcurrency `json:"adaptive,omitempty"`
+
+	// Thresholds are pass/fail limits on the results, shown in reports and
+	// checked by headless runs. With an error rate limit, failed requests
+	// within it do not fail the test.
+	Thresholds *Thresholds `json:"thresholds,omitempty"`
+
+	// Driver names the protocol driver that sends the requests; empty means
+	// http. Other drivers are registered with benchmark.RegisterDriver and
+	// may use other URL schemes.
+	Driver string `json:"driver,omitempty"`
+}
+
+// IsHTTP reports whether the config uses the default HTTP driver.
+func (c BenchmarkConfig) IsHTTP() bool {
+	return c.Driver == "" || strings.EqualFold(c.Driver, "http")
 }
 
+// SkipVerify reports whether TLS certificate verification is turned off.
+func (c BenchmarkConfig) SkipVerify() bool {
+	return c.Insecure != nil && *c.Insecure
+}
+
+// Windows returns the warm-up, measured and cool-down durations of a run.
+// Unparsable durations are 0; Check reports them.
+func (c BenchmarkConfig) Windows() (warmup, measure, cooldown time.Duration) {
+	warmup, _ = time.ParseDuration(c.Warmup)
+	measure, _ = time.ParseDuration(c.Duration)
+	cooldown, _ = time.ParseDuration(c.Cooldown)
+	return warmup, measure, cooldown
+}
+
+// MixRequest returns a copy of c describing only the i-th request of its mix.
+func (c BenchmarkConfig) MixRequest(i int) BenchmarkConfig {
+	spec := c.Mix[i]
+	out := c
+	out.Mix = nil
+	out.TargetURL = spec.URL
+	out.Method = spec.Method
+	out.Payload = spec.Payload
+	out.PayloadFile = ""
+	out.Form = nil
+	if len(spec.Headers) > 0 {
+		out.Headers = make(map[string]string, len(c.Headers)+len(spec.Headers))
+		for k, v := range c.Headers {
+			out.Headers[k] = v
+		}
+		for k, v := range spec.Headers {
+			out.Headers[k] = v
+		}
+	}
+	return out
+}
+
+// MapValues returns a copy of c with fn applied to every URL, header name and
+// value, and payload, including those of its request mix.
+func (c BenchmarkConfig) MapValues(fn func(string) string) BenchmarkConfig {
+	mapHeaders := func(h map[string]string) map[string]string {
+		if h == nil {
+			return nil
+		}
+		out := make(map[string]string, len(h))
+		for k, v := range h {
+			out[fn(k)] = fn(v)
+		}
+		return out
+	}
+
+	out := c
+	out.TargetURL = fn(c.TargetURL)
+	out.Headers = mapHeaders(c.Headers)
+	out.Payload = fn(c.Payload)
+	out.PayloadFile = fn(c.PayloadFile)
+	if c.Arrivals != nil {
+		arrivals := *c.Arrivals
+		arrivals.Trace = fn(arrivals.Trace)
+		out.Arrivals = &arrivals
+	}
+	if c.Form != nil {
+		out.Form = make([]FormField, len(c.Form))
+		for i, f := range c.Form {
+			f.Name, f.Value, f.File = fn(f.Name), fn(f.Value), fn(f.File)
+			out.Form[i] = f
+		}
+	}
+	if len(c.Mix) > 0 {
+		out.Mix = make([]RequestSpec, len(c.Mix))
+		for i, spec := range c.Mix {
+			spec.URL = fn(spec.URL)
+			spec.Headers = mapHeaders(spec.Headers)
+			spec.Payload = fn(spec.Payload)
+			out.Mix[i] = spec
+		}
+	}
+	return out
+}
+
+// FieldError is a validation problem with a config field, named by its JSON
+// path such as "threads" or "mix[1].url".
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e FieldError) Error() string { return e.Err.Error() }
+
+// Validate checks the config and returns its first problem, if any.
 func (c *BenchmarkConfig) Validate() error {
-	c.TargetURL = strings.TrimSpace(c.TargetURL)
-	if c.TargetURL == "" {
-		return fmt.Errorf("target URL cannot be empty")
+	if problems := c.Check(); len(problems) > 0 {
+		return problems[0].Err
 	}
-	parsedURL, err := url.ParseRequestURI(c.TargetURL)
-	if err != nil {
-		return fmt.Errorf("invalid target URL: %w", err)
+	return nil
+}
+
+// Check validates the config and returns every problem found.
+func (c *BenchmarkConfig) Check() []FieldError {
+	var problems []FieldError
+	add := func(field string, format string, args ...any) {
+		problems = append(problems, FieldError{Field: field, Err: fmt.Errorf(format, args...)})
 	}
-	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-		return fmt.Errorf("target URL must use http or https scheme")
+
+	// URLs referencing variables or secrets are checked once resolved, before
+	// the test runs.
+	c.TargetURL = strings.TrimSpace(c.TargetURL)
+	var parsedURL *url.URL
+	if !HasReferences(c.TargetURL) {
+		if c.TargetURL == "" {
+			add("url", "target URL cannot be empty")
+		} else if u, err := url.ParseRequestURI(c.TargetURL); err != nil {
+			add("url", "invalid target URL: %w", err)
+		} else if !c.IsHTTP() {
+			// Other drivers define their own schemes.
+			parsedURL = u
+		} else if u.Scheme != "http" && u.Scheme != "https" {
+			add("url", "target URL must use http or https scheme")
+		} else {
+			parsedURL = u
+		}
 	}
 
-	if c.Threads <= 0 {
-		return fmt.Errorf("threads must be greater than 0")
+	if c.Threads <= 0 && c.Users == nil && c.Arrivals == nil && c.Search == nil && c.Adaptive == nil {
+		add("threads", "threads must be greater than 0")
 	}
 	if c.Connections <= 0 {
-		return fmt.Errorf("connections must be greater than 0")
+		add("connections", "connections must be greater than 0")
 	}
 	// if c.Threads > c.Connections {
 	//  return fmt.Errorf("threads (%d) should not exceed connections (%d) for optimal use", c.Threads, c.Connections)
 	// }
 
 	if c.Duration == "" {
-		return fmt.Errorf("duration cannot be empty")
+		add("duration", "duration cannot be empty")
+	} else if _, err := time.ParseDuration(c.Duration); err != nil {
+		add("duration", "invalid duration format: %w", err)
 	}
-	_, err = time.ParseDuration(c.Duration)
-	if err != nil {
-		return fmt.Errorf("invalid duration format: %w", err)
+	for _, w := range []struct{ field, value string }{{"warmup", c.Warmup}, {"cooldown", c.Cooldown}} {
+		if w.value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(w.value); err != nil {
+			add(w.field, "invalid %s format: %w", w.field, err)
+		} else if d < 0 {
+			add(w.field, "%s must not be negative", w.field)
+		}
+	}
+
+	for i, spec := range c.Mix {
+		field := fmt.Sprintf("mix[%d]", i)
+		if spec.Weight <= 0 {
+			add(field+".weight", "mix request %d: weight must be greater than 0", i+1)
+		}
+		if HasReferences(spec.URL) {
+			continue
+		}
+		mixURL, err := url.ParseRequestURI(strings.TrimSpace(spec.URL))
+		if err != nil {
+			add(field+".url", "mix request %d: invalid URL: %w", i+1, err)
+		} else if parsedURL != nil && (mixURL.Scheme != parsedURL.Scheme || mixURL.Host != parsedURL.Host) {
+			add(field+".url", "mix request %d: URL must use the same scheme and host as the target URL", i+1)
+		}
 	}
 
-	if (c.Method == "POST" || c.Method == "PUT" || c.Method == "PATCH") && c.Payload == "" {
-		// return fmt.Errorf("payload cannot be empty for %s method", c.Method)
+	if c.BodyType != "" && BodyContentType(c.BodyType) == "" {
+		add("body_type", "unknown body type %q (use %s)", c.BodyType, strings.Join(BodyTypes, ", "))
 	}
-	// Add other method/payload validation as needed
+	if strings.EqualFold(c.BodyType, BodyBinary) && c.PayloadFile == "" {
+		add("payload_file", "a binary body needs a payload file")
+	}
+	if c.PayloadFile != "" && c.Payload != "" {
+		add("payload_file", "set either payload or payload_file, not both")
+	}
+	if len(c.Form) > 0 {
+		multipart := strings.EqualFold(c.BodyType, BodyMultipart)
+		if !IsFormBody(c.BodyType) {
+			add("form", "form fields need body_type %s or %s", BodyForm, BodyMultipart)
+		}
+		if c.Payload != "" || c.PayloadFile != "" {
+			add("form", "set either form or payload, not both")
+		}
+		for i, f := range c.Form {
+			field := fmt.Sprintf("form[%d]", i)
+			if strings.TrimSpace(f.Name) == "" {
+				add(field+".name", "form field %d: name cannot be empty", i+1)
+			}
+			if f.File != "" && f.Value != "" {
+				add(field+".file", "form field %d: set either value or file, not both", i+1)
+			}
+			if !multipart && (f.File != "" || f.ContentType != "" || f.Filename != "") {
+				add(field+".file", "form field %d: files and part options need body_type %s", i+1, BodyMultipart)
+			}
+		}
+	}
+
+	if c.Users != nil {
+		problems = append(problems, c.Users.check()...)
+	}
+	if c.Arrivals != nil {
+		arrivals := *c.Arrivals
+		if c.Search != nil {
+			// The search sets the rate of each step.
+			arrivals.Rate = c.Search.StartRate
+		}
+		problems = append(problems, arrivals.check()...)
+		if c.Users != nil {
+			add("arrivals", "set either users or arrivals, not both")
+		}
+	}
+	if c.Adaptive != nil {
+		problems = append(problems, c.Adaptive.check(c.Connections)...)
+		if c.Users != nil || c.Arrivals != nil || c.Search != nil {
+			add("adaptive", "adaptive concurrency cannot be combined with users, arrivals or search")
+		}
+	}
+	if c.Thresholds != nil {
+		problems = append(problems, c.Thresholds.check("thresholds.")...)
+	}
+	if c.Search != nil {
+		problems = append(problems, c.Search.check()...)
+		if c.Users != nil {
+			add("search", "a capacity search runs open-model steps and cannot use users")
+		}
+	}
+
+	// Any method may carry a body; an empty method is GET.
+	if c.Method != "" {
+		if err := CheckMethod(c.Method); err != nil {
+			add("method", "%w", err)
+		}
+	}
+	for i, spec := range c.Mix {
+		if spec.Method != "" {
+			if err := CheckMethod(spec.Method); err != nil {
+				add(fmt.Sprintf("mix[%d].method", i), "mix request %d: %w", i+1, err)
+			}
+		}
+	}
+
+	return problems
+}
 
+// CheckMethod checks that method is a valid HTTP method name: a token such as
+// GET, PROPFIND or a custom verb. CONNECT is rejected because it opens a
+// tunnel instead of making a request.
+func CheckMethod(method string) error {
+	if method == "" {
+		return fmt.Errorf("HTTP method cannot be empty")
+	}
+	for _, r := range method {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("!#$%&'*+-.^_`|~", r)) {
+			return fmt.Errorf("invalid HTTP method %q: %q is not allowed in a method name", method, r)
+		}
+	}
+	if strings.EqualFold(method, "CONNECT") {
+		return fmt.Errorf("the CONNECT method is not supported")
+	}
 	return nil
 }
 
-func LoadTestCollections(dirPath string) ([]TestCollection, error) {
+// LoadTestCollections reads every collection directory under dirPath. Test
+// files that cannot be loaded are skipped; they and any other warnings are
+// listed in the returned report.
+func LoadTestCollections(dirPath string) ([]TestCollection, *LoadReport, error) {
 	collections := []TestCollection{}
+	report := &LoadReport{}
 
 	entries, err := os.ReadDir(dirPath)
 	if err != nil {
-		return nil, fmt.Errorf("reading directory %s: %w", dirPath, err)
+		return nil, report, fmt.Errorf("reading directory %s: %w", dirPath, err)
 	}
 
 	for _, entry := range entries {
@@ -84,59 +375,85 @@ func LoadTestCollections(dirPath string) ([]TestCollection, error) {
 
 			testEntries, err := os.ReadDir(collectionPath)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: reading collection directory %s: %v. Skipping.\n", collectionPath, err)
+				report.Problems = append(report.Problems, Problem{
+					File: collectionPath, Message: fmt.Sprintf("reading collection directory: %v", err),
+				})
 				continue
 			}
 
-			for _, testEntry := range testEntries {
-				if !testEntry.IsDir() && strings.HasSuffix(testEntry.Name(), ".json") {
-					testName := strings.TrimSuffix(testEntry.Name(), ".json")
-					testPath := filepath.Join(collectionPath, testEntry.Name())
-
-					content, err := os.ReadFile(testPath)
-					if err != nil {
-						fmt.Fprintf(os.Stderr, "Warning: reading test file %s: %v. Skipping test.\n", testPath, err)
-						continue
-					}
-
-					var config BenchmarkConfig
-					if err := json.Unmarshal(content, &config); err != nil {
-						fmt.Fprintf(os.Stderr, "Warning: parsing test file %s: %v. Skipping test.\n", testPath, err)
-						continue
-					}
-					if err := config.Validate(); err != nil {
-						fmt.Fprintf(os.Stderr, "Warning: invalid config in test file %s: %v. Skipping test.\n", testPath, err)
-						continue
-					}
-					collection.Tests = append(collection.Tests, Test{Name: testName, Config: config})
+			if settingsPath := findCollectionFile(collectionPath); settingsPath != "" {
+				settings, problems := LoadCollectionFile(settingsPath)
+				report.Problems = append(report.Problems, problems...)
+				if !HasErrors(problems) {
+					collection.Defaults = settings.Defaults
+					collection.Variables = settings.Variables
+					collection.Environments = settings.Environments
 				}
 			}
-			if len(collection.Tests) > 0 {
-				collections = append(collections, collection)
+
+			seen := make(map[string]string)
+			for _, testEntry := range testEntries {
+				testName, ok := testFileName(testEntry.Name())
+				if testEntry.IsDir() || !ok || isCollectionFile(testEntry.Name()) {
+					continue
+				}
+				testPath := filepath.Join(collectionPath, testEntry.Name())
+				if other, dup := seen[testName]; dup {
+					report.Problems = append(report.Problems, Problem{
+						File: testPath, Message: fmt.Sprintf("test %q is already defined by %s; skipping", testName, other),
+					})
+					continue
+				}
+
+				config, problems, migratedFrom := loadTestFile(testPath, collection.Defaults)
+				report.Problems = append(report.Problems, problems...)
+				if HasErrors(problems) {
+					continue
+				}
+				if migratedFrom != 0 {
+					report.Migrated = append(report.Migrated, testPath)
+				}
+				seen[testName] = testEntry.Name()
+				collection.Tests = append(collection.Tests, Test{Name: testName, Config: config})
 			}
+			collections = append(collections, collection)
 		}
 	}
-	return collections, nil
+	return collections, report, nil
 }
 
+// SaveTestToCollection writes a new test file, creating the collection if
+// needed. It fails with ErrTestExists rather than replace an existing test;
+// use ReplaceTestInCollection to overwrite.
 func SaveTestToCollection(
 	baseDir string,
 	collectionName string,
 	testName string,
 	cfg BenchmarkConfig,
 ) error {
-	if strings.TrimSpace(collectionName) == "" {
-		return fmt.Errorf("collection name cannot be empty")
-	}
-	if strings.TrimSpace(testName) == "" {
-		return fmt.Errorf("test name cannot be empty")
-	}
-	collectionName = SanitizeFilename(collectionName)
-	testName = SanitizeFilename(testName)
+	return saveTest(baseDir, collectionName, testName, cfg, false)
+}
+
+// ReplaceTestInCollection writes a test file, overwriting an existing test of
+// the same name in its current format.
+func ReplaceTestInCollection(baseDir, collectionName, testName string, cfg BenchmarkConfig) error {
+	return saveTest(baseDir, collectionName, testName, cfg, true)
+}
 
+func saveTest(baseDir, collectionName, testName string, cfg BenchmarkConfig, overwrite bool) error {
+	if _, _, err := checkNames(collectionName, testName); err != nil {
+		return err
+	}
+	collectionName, testName = SavedNames(baseDir, collectionName, testName)
 	collectionPath := filepath.Join(baseDir, collectionName)
-	testFileName := testName + ".json"
-	testFilePath := filepath.Join(collectionPath, testFileName)
+
+	testFilePath := filepath.Join(collectionPath, testName+".json")
+	if existing, ok := findTestFile(collectionPath, testName); ok {
+		if !overwrite {
+			return fmt.Errorf("%w: '%s' in collection '%s'", ErrTestExists, testName, collectionName)
+		}
+		testFilePath = existing // Keep YAML definitions in YAML
+	}
 
 	if _, err := os.Stat(baseDir); os.IsNotExist(err) {
 		if err := os.MkdirAll(baseDir, 0755); err != nil {
@@ -150,23 +467,23 @@ func SaveTestToCollection(
 		}
 	}
 
-	if _, err := os.Stat(testFilePath); err == nil {
-		// return fmt.Errorf("test file '%s' already exists in collection '%s'", testName, collectionName)
-	}
-
-	jsonData, err := json.MarshalIndent(cfg, "", "  ")
+	data, err := encodeTestFile(testFilePath, cfg)
 	if err != nil {
-		return fmt.Errorf("failed to marshal test config to JSON: %w", err)
+		return err
 	}
 
-	err = os.WriteFile(testFilePath, jsonData, 0644)
-	if err != nil {
+	if err := os.WriteFile(testFilePath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write test file %s: %w", testFilePath, err)
 	}
 
 	return nil
 }
 
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
 // SanitizeFilename removes or replaces characters that are problematic in filenames.
 func SanitizeFilename(name string) string {
 	name = strings.TrimSpace(name)
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrTestExists is returned when saving, renaming, duplicating or moving a
+// test would replace an existing one.
+var ErrTestExists = errors.New("test already exists")
+
+// ErrCollectionExists is returned when creating or renaming a collection to a
+// name that is already taken.
+var ErrCollectionExists = errors.New("collection already exists")
+
+// findTestFile returns the path of the named test's file in a collection
+// directory, whichever format it is stored in.
+func findTestFile(collectionPath, testName string) (string, bool) {
+	for _, ext := range []string{".json", ".yaml", ".yml"} {
+		p := filepath.Join(collectionPath, testName+ext)
+		if fileExists(p) {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// TestExists reports whether the collection contains the named test.
+func TestExists(baseDir, collectionName, testName string) bool {
+	collectionName, testName = SavedNames(baseDir, collectionName, testName)
+	_, ok := findTestFile(filepath.Join(baseDir, collectionName), testName)
+	return ok
+}
+
+// SavedNames returns the collection and test names a test given by the user is
+// saved under: existing ones of those exact names, or else sanitized new ones.
+func SavedNames(baseDir, collectionName, testName string) (string, string) {
+	collectionName = targetCollection(baseDir, collectionName)
+	if checkExisting("test", testName) == nil {
+		if _, ok := findTestFile(filepath.Join(baseDir, collectionName), testName); ok {
+			return collectionName, testName
+		}
+	}
+	return collectionName, SanitizeFilename(testName)
+}
+
+// checkNames sanitizes the collection and test names and rejects empty or
+// reserved ones.
+func checkNames(collectionName, testName string) (string, string, error) {
+	if strings.TrimSpace(collectionName) == "" {
+		return "", "", fmt.Errorf("collection name cannot be empty")
+	}
+	if strings.TrimSpace(testName) == "" {
+		return "", "", fmt.Errorf("test name cannot be empty")
+	}
+	collectionName = SanitizeFilename(collectionName)
+	testName = SanitizeFilename(testName)
+	if testName == collectionFileBase {
+		return "", "", fmt.Errorf("test name %q is reserved for collection settings", testName)
+	}
+	return collectionName, testName, nil
+}
+
+// checkExisting checks the name of an existing collection or test, as it was
+// loaded from its file name. Such names are used as they are: sanitizing one
+// that holds, say, a space would name a different file.
+func checkExisting(kind, name string) error {
+	if name == "" || name == "." || name == ".." || name != filepath.Base(name) {
+		return fmt.Errorf("invalid %s name %q", kind, name)
+	}
+	return nil
+}
+
+// targetCollection returns the directory name for a collection given by the
+// user: an existing collection of that exact name, or else the sanitized name
+// of a new one.
+func targetCollection(baseDir, name string) string {
+	if checkExisting("collection", name) == nil && fileExists(filepath.Join(baseDir, name)) {
+		return name
+	}
+	return SanitizeFilename(name)
+}
+
+// checkExistingTest checks the names of an existing test and its collection.
+func checkExistingTest(collectionName, testName string) error {
+	if err := checkExisting("collection", collectionName); err != nil {
+		return err
+	}
+	return checkExisting("test", testName)
+}
+
+// CreateCollection creates an empty collection directory.
+func CreateCollection(baseDir, name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("collection name cannot be empty")
+	}
+	path := filepath.Join(baseDir, SanitizeFilename(name))
+	if fileExists(path) {
+		return fmt.Errorf("%w: '%s'", ErrCollectionExists, SanitizeFilename(name))
+	}
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return fmt.Errorf("failed to create collection directory %s: %w", path, err)
+	}
+	return nil
+}
+
+// RenameCollection renames a collection directory, refusing to merge into an
+// existing collection. oldName is the collection's name as loaded.
+func RenameCollection(baseDir, oldName, newName string) error {
+	if err := checkExisting("collection", oldName); err != nil {
+		return err
+	}
+	if strings.TrimSpace(newName) == "" {
+		return fmt.Errorf("collection name cannot be empty")
+	}
+	oldPath := filepath.Join(baseDir, oldName)
+	newPath := filepath.Join(baseDir, SanitizeFilename(newName))
+	if oldPath == newPath {
+		return nil
+	}
+	if fileExists(newPath) {
+		return fmt.Errorf("%w: '%s'", ErrCollectionExists, SanitizeFilename(newName))
+	}
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return fmt.Errorf("failed to rename collection: %w", err)
+	}
+	return nil
+}
+
+// DeleteCollection removes a collection directory and every test in it. name
+// is the collection's name as loaded.
+func DeleteCollection(baseDir, name string) error {
+	if err := checkExisting("collection", name); err != nil {
+		return err
+	}
+	path := filepath.Join(baseDir, name)
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return fmt.Errorf("collection '%s' does not exist", name)
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("failed to delete collection '%s': %w", name, err)
+	}
+	return nil
+}
+
+// DeleteTest removes a test file. The names are those of the loaded
+// collection and test.
+func DeleteTest(baseDir, collectionName, testName string) error {
+	if err := checkExistingTest(collectionName, testName); err != nil {
+		return err
+	}
+	path, ok := findTestFile(filepath.Join(baseDir, collectionName), testName)
+	if !ok {
+		return fmt.Errorf("test '%s' not found in collection '%s'", testName, collectionName)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to delete test '%s': %w", testName, err)
+	}
+	return nil
+}
+
+// RenameTest renames a test within its collection, keeping its file format.
+func RenameTest(baseDir, collectionName, oldName, newName string) error {
+	return MoveTest(baseDir, collectionName, oldName, collectionName, newName)
+}
+
+// MoveTest moves a test, named as loaded, to another collection, optionally
+// renaming it. The target collection is created if needed; an existing test
+// is never replaced. A new name is sanitized; passing the test's own name
+// keeps it.
+func MoveTest(baseDir, fromCollection, testName, toCollection, newName string) error {
+	if err := checkExistingTest(fromCollection, testName); err != nil {
+		return err
+	}
+	if strings.TrimSpace(toCollection) == "" {
+		return fmt.Errorf("collection name cannot be empty")
+	}
+	toCollection = targetCollection(baseDir, toCollection)
+	if newName != testName {
+		var err error
+		if _, newName, err = checkNames(toCollection, newName); err != nil {
+			return err
+		}
+	}
+	src, ok := findTestFile(filepath.Join(baseDir, fromCollection), testName)
+	if !ok {
+		return fmt.Errorf("test '%s' not found in collection '%s'", testName, fromCollection)
+	}
+	dstDir := filepath.Join(baseDir, toCollection)
+	if fromCollection == toCollection && testName == newName {
+		return nil
+	}
+	if _, exists := findTestFile(dstDir, newName); exists {
+		return fmt.Errorf("%w: '%s' in collection '%s'", ErrTestExists, newName, toCollection)
+	}
+	if err := os.MkdirAll(dstDir, 0755); err != nil {
+		return fmt.Errorf("failed to create collection directory %s: %w", dstDir, err)
+	}
+	if err := os.Rename(src, filepath.Join(dstDir, newName+filepath.Ext(src))); err != nil {
+		return fmt.Errorf("failed to move test '%s': %w", testName, err)
+	}
+	return nil
+}
+
+// DuplicateTest copies a test, named as loaded, under a new name in the same
+// collection. The file is copied as is, so variable and secret references are
+// kept.
+func DuplicateTest(baseDir, collectionName, testName, newName string) error {
+	if err := checkExistingTest(collectionName, testName); err != nil {
+		return err
+	}
+	_, newName, err := checkNames(collectionName, newName)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(baseDir, collectionName)
+	src, ok := findTestFile(dir, testName)
+	if !ok {
+		return fmt.Errorf("test '%s' not found in collection '%s'", testName, collectionName)
+	}
+	if _, exists := findTestFile(dir, newName); exists {
+		return fmt.Errorf("%w: '%s' in collection '%s'", ErrTestExists, newName, collectionName)
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read test '%s': %w", testName, err)
+	}
+	dst := filepath.Join(dir, newName+filepath.Ext(src))
+	if err := os.WriteFile(dst, data, 0644); err != nil {
+		return fmt.Errorf("failed to write test file %s: %w", dst, err)
+	}
+	return nil
+}
@@ -0,0 +1,492 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion is the version of the test file format written by this build.
+//
+// Version 1 is the original, unversioned format. The engine compared methods
+// case-sensitively, so a lower-case "post" silently sent no payload; version 2
+// stores methods in upper case and records the version in every file.
+const SchemaVersion = 2
+
+// migrations upgrade a raw test document from the keyed version to the next.
+var migrations = map[int]func(raw map[string]any){
+	1: func(raw map[string]any) {
+		method, _ := raw["method"].(string)
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if method == "" {
+			method = "GET"
+		}
+		raw["method"] = method
+	},
+}
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// Problem is an issue found in a test file, with its position when known.
+type Problem struct {
+	File    string
+	Line    int
+	Column  int
+	Field   string
+	Message string
+	Warning bool // The file is still usable
+}
+
+func (p Problem) String() string {
+	var b strings.Builder
+	b.WriteString(p.File)
+	if p.Line > 0 {
+		fmt.Fprintf(&b, ":%d", p.Line)
+		if p.Column > 0 {
+			fmt.Fprintf(&b, ":%d", p.Column)
+		}
+	}
+	if p.Warning {
+		b.WriteString(": warning")
+	}
+	b.WriteString(": ")
+	if p.Field != "" {
+		b.WriteString(p.Field + ": ")
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// HasErrors reports whether any problem prevents a file from being used.
+func HasErrors(problems []Problem) bool {
+	for _, p := range problems {
+		if !p.Warning {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadReport collects what happened while loading test collections.
+type LoadReport struct {
+	Problems []Problem
+	Migrated []string // Files loaded from an older schema version
+}
+
+// testFileName returns the test name for a test file name, or false if the
+// file is not a test definition.
+func testFileName(fileName string) (string, bool) {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	switch ext {
+	case ".json", ".yaml", ".yml":
+	default:
+		return "", false
+	}
+	name := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	if name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+func isYAMLFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// LoadTestFile reads a JSON or YAML test file, migrates it to the current
+// schema version and validates it with its collection's defaults applied. The
+// config is returned as written; TestCollection.Resolve applies the defaults.
+// It returns every problem found, and the schema version the file was
+// migrated from (0 if it was current).
+func LoadTestFile(path string) (BenchmarkConfig, []Problem, int) {
+	var defaults BenchmarkConfig
+	if settingsPath := findCollectionFile(filepath.Dir(path)); settingsPath != "" {
+		settings, problems := LoadCollectionFile(settingsPath)
+		if HasErrors(problems) {
+			return BenchmarkConfig{}, []Problem{{
+				File: path, Message: fmt.Sprintf("collection file %s has errors", filepath.Base(settingsPath)),
+			}}, 0
+		}
+		defaults = settings.Defaults
+	}
+	return loadTestFile(path, defaults)
+}
+
+// CollectionForFile returns the collection a test file belongs to: its
+// directory, with the defaults, variables and environments of its collection
+// file.
+func CollectionForFile(path string) (TestCollection, []Problem) {
+	dir := filepath.Dir(path)
+	if abs, err := filepath.Abs(dir); err == nil {
+		// Name a relative "." after the directory it stands for.
+		dir = abs
+	}
+	collection := TestCollection{Name: filepath.Base(dir)}
+	settingsPath := findCollectionFile(dir)
+	if settingsPath == "" {
+		return collection, nil
+	}
+	settings, problems := LoadCollectionFile(settingsPath)
+	collection.Defaults = settings.Defaults
+	collection.Variables = settings.Variables
+	collection.Environments = settings.Environments
+	return collection, problems
+}
+
+func loadTestFile(path string, defaults BenchmarkConfig) (BenchmarkConfig, []Problem, int) {
+	var cfg BenchmarkConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, []Problem{{File: path, Message: fmt.Sprintf("reading test file: %v", err)}}, 0
+	}
+
+	raw, root, problem := parseDocument(path, data)
+	if problem != nil {
+		return cfg, []Problem{*problem}, 0
+	}
+	at := func(field, message string, warning bool) Problem {
+		line, col := locate(root, field)
+		return Problem{File: path, Line: line, Column: col, Field: field, Message: message, Warning: warning}
+	}
+
+	version := 1
+	if v, ok := raw["version"]; ok && v != nil {
+		n, ok := asInt(v)
+		if !ok || n < 1 {
+			return cfg, []Problem{at("version", "version must be a positive integer", false)}, 0
+		}
+		version = n
+	}
+	if version > SchemaVersion {
+		return cfg, []Problem{at("version", fmt.Sprintf("schema version %d is newer than supported version %d", version, SchemaVersion), false)}, 0
+	}
+	migratedFrom := 0
+	for v := version; v < SchemaVersion; v++ {
+		migrations[v](raw)
+		migratedFrom = version
+	}
+	raw["version"] = SchemaVersion
+
+	problems := decodeFields(raw, reflect.ValueOf(&cfg).Elem(), "", at)
+	merged := applyDefaults(cfg, defaults)
+	reported := make(map[string]bool, len(problems))
+	for _, p := range problems {
+		reported[p.Field] = true
+	}
+	for _, fe := range merged.Check() {
+		if !reported[fe.Field] { // A field that failed to decode is already reported
+			problems = append(problems, at(fe.Field, fe.Err.Error(), false))
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+	return cfg, problems, migratedFrom
+}
+
+// MigrateTestFile rewrites a test file from an older schema version in the
+// current one, keeping its format. It reports whether the file was changed.
+func MigrateTestFile(path string) (bool, error) {
+	if _, problems, _ := LoadTestFile(path); HasErrors(problems) {
+		return false, fmt.Errorf("%s has problems; fix them before migrating", path)
+	}
+	cfg, _, migratedFrom := loadTestFile(path, BenchmarkConfig{})
+	if migratedFrom == 0 {
+		return false, nil
+	}
+	data, err := encodeTestFile(path, cfg)
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return false, fmt.Errorf("writing %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// encodeTestFile serializes cfg at the current schema version as JSON, or as
+// YAML if path has a YAML extension.
+func encodeTestFile(path string, cfg BenchmarkConfig) ([]byte, error) {
+	cfg.Version = SchemaVersion
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // Keep payloads such as "a=1&b=2" readable
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(cfg); err != nil {
+		return nil, fmt.Errorf("failed to marshal test config to JSON: %w", err)
+	}
+	if !isYAMLFile(path) {
+		return buf.Bytes(), nil
+	}
+
+	// Round-trip through a node so YAML keys keep the JSON field order.
+	var node yaml.Node
+	if err := yaml.Unmarshal(buf.Bytes(), &node); err != nil {
+		return nil, fmt.Errorf("failed to convert test config to YAML: %w", err)
+	}
+	clearStyle(&node)
+	var out bytes.Buffer
+	yenc := yaml.NewEncoder(&out)
+	yenc.SetIndent(2)
+	if err := yenc.Encode(&node); err != nil {
+		return nil, fmt.Errorf("failed to marshal test config to YAML: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// clearStyle drops the flow style the JSON source gives every node.
+func clearStyle(n *yaml.Node) {
+	n.Style &^= yaml.FlowStyle
+	if n.Kind == yaml.ScalarNode && n.Style == yaml.DoubleQuotedStyle && n.Tag == "!!str" {
+		n.Style = 0
+	}
+	for _, c := range n.Content {
+		clearStyle(c)
+	}
+}
+
+// parseDocument parses a test file into a generic map and a YAML node tree
+// used to locate fields.
+func parseDocument(path string, data []byte) (map[string]any, *yaml.Node, *Problem) {
+	var root yaml.Node
+	var generic any
+
+	if isYAMLFile(path) {
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			p := &Problem{File: path, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+			if m := yamlLinePattern.FindStringSubmatch(p.Message); m != nil {
+				p.Line, _ = strconv.Atoi(m[1])
+				p.Message = strings.TrimPrefix(p.Message, m[0]+": ")
+			}
+			return nil, nil, p
+		}
+		if err := root.Decode(&generic); err != nil {
+			return nil, nil, &Problem{File: path, Message: err.Error()}
+		}
+		generic = NormalizeYAML(generic)
+	} else {
+		if err := json.Unmarshal(data, &generic); err != nil {
+			p := &Problem{File: path, Message: err.Error()}
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				p.Line, p.Column = offsetPosition(data, syntaxErr.Offset)
+			}
+			return nil, nil, p
+		}
+		// JSON is valid YAML; the node tree only supplies positions.
+		_ = yaml.Unmarshal(data, &root)
+	}
+
+	raw, ok := generic.(map[string]any)
+	if !ok {
+		return nil, nil, &Problem{File: path, Line: 1, Message: "test file must contain an object"}
+	}
+	return raw, &root, nil
+}
+
+// decodeFields decodes raw into the struct v field by field, so that every
+// unknown field and type mismatch is reported rather than only the first.
+func decodeFields(raw map[string]any, v reflect.Value, prefix string, at func(field, message string, warning bool) Problem) []Problem {
+	var problems []Problem
+	t := v.Type()
+	known := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			known[name] = i
+		}
+	}
+
+	keys := make([]string, 0, len(raw))
+	for k := range raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		field := prefix + key
+		idx, ok := known[key]
+		if !ok {
+			problems = append(problems, at(field, "unknown field", true))
+			continue
+		}
+		fv := v.Field(idx)
+
+		// Recurse into structs and lists of structs for per-field positions.
+		if obj, isObj := raw[key].(map[string]any); isObj && fv.Kind() == reflect.Struct {
+			problems = append(problems, decodeFields(obj, fv, field+".", at)...)
+			continue
+		}
+		if obj, isObj := raw[key].(map[string]any); isObj && fv.Kind() == reflect.Pointer && fv.Type().Elem().Kind() == reflect.Struct {
+			target := reflect.New(fv.Type().Elem())
+			problems = append(problems, decodeFields(obj, target.Elem(), field+".", at)...)
+			fv.Set(target)
+			continue
+		}
+		if list, isList := raw[key].([]any); isList && fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Struct {
+			out := reflect.MakeSlice(fv.Type(), len(list), len(list))
+			for i, elem := range list {
+				elemField := fmt.Sprintf("%s[%d]", field, i)
+				obj, isObj := elem.(map[string]any)
+				if !isObj {
+					problems = append(problems, at(elemField, "expected an object", false))
+					continue
+				}
+				problems = append(problems, decodeFields(obj, out.Index(i), elemField+".", at)...)
+			}
+			fv.Set(out)
+			continue
+		}
+
+		data, err := json.Marshal(raw[key])
+		if err != nil {
+			problems = append(problems, at(field, err.Error(), false))
+			continue
+		}
+		target := reflect.New(fv.Type())
+		if err := json.Unmarshal(data, target.Interface()); err != nil {
+			msg := err.Error()
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				msg = fmt.Sprintf("expected %s, got %s", describeType(typeErr.Type), typeErr.Value)
+				if typeErr.Field != "" {
+					field += "." + typeErr.Field
+				}
+			}
+			problems = append(problems, at(field, msg, false))
+			continue
+		}
+		fv.Set(target.Elem())
+	}
+	return problems
+}
+
+func describeType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	}
+	return t.String()
+}
+
+// locate returns the line and column of a field path such as "mix[1].url"
+// in a YAML node tree, falling back to the closest enclosing node.
+func locate(root *yaml.Node, field string) (int, int) {
+	if root == nil || root.Kind == 0 {
+		return 0, 0
+	}
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	line, col := node.Line, node.Column
+	for _, part := range strings.Split(field, ".") {
+		name, index := part, -1
+		if i := strings.IndexByte(part, '['); i >= 0 && strings.HasSuffix(part, "]") {
+			name = part[:i]
+			index, _ = strconv.Atoi(part[i+1 : len(part)-1])
+		}
+		if node.Kind != yaml.MappingNode {
+			break
+		}
+		var value *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == name {
+				line, col = node.Content[i].Line, node.Content[i].Column
+				value = node.Content[i+1]
+				break
+			}
+		}
+		if value == nil {
+			break
+		}
+		node = value
+		if index >= 0 {
+			if node.Kind != yaml.SequenceNode || index >= len(node.Content) {
+				break
+			}
+			node = node.Content[index]
+			line, col = node.Line, node.Column
+		}
+	}
+	return line, col
+}
+
+func offsetPosition(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	line, col := 1, 1
+	for _, b := range data[:offset] {
+		if b == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+func asInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		if n == float64(int(n)) {
+			return int(n), true
+		}
+	}
+	return 0, false
+}
+
+// NormalizeYAML converts maps with non-string keys, which YAML permits, into
+// map[string]any so documents can be walked and decoded like JSON.
+func NormalizeYAML(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			t[k] = NormalizeYAML(val)
+		}
+		return t
+	case map[any]any:
+		m := make(map[string]any, len(t))
+		for k, val := range t {
+			m[fmt.Sprint(k)] = NormalizeYAML(val)
+		}
+		return m
+	case []any:
+		for i, val := range t {
+			t[i] = NormalizeYAML(val)
+		}
+		return t
+	}
+	return v
+}
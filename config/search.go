@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Defaults of a capacity search.
+const (
+	DefaultSearchGrowth    = 2.0
+	DefaultSearchPrecision = 0.05
+)
+
+// CapacitySearch finds the highest request rate the target sustains within
+// an SLO. It runs fixed-rate open-model steps, raising the rate until the SLO
+// breaks, then bisects between the last passing and first failing rates.
+type CapacitySearch struct {
+	StartRate float64 `json:"start_rate"`         // Rate of the first step, in req/s
+	MaxRate   float64 `json:"max_rate,omitempty"` // Highest rate tried; 0 means no limit
+	// StepDuration is the length of each step; it defaults to the test's
+	// duration.
+	StepDuration string  `json:"step_duration,omitempty"`
+	Growth       float64 `json:"growth,omitempty"`    // Rate multiplier while ramping; defaults to 2
+	Precision    float64 `json:"precision,omitempty"` // Bisect until the bounds are this fraction apart; defaults to 0.05
+	MaxSteps     int     `json:"max_steps,omitempty"` // Stop after this many steps; 0 means no limit
+	// SLO is what every step must meet to pass. A step also fails if arrivals
+	// were dropped, or it achieved less than 90% of its rate.
+	SLO Thresholds `json:"slo"`
+}
+
+// GrowthFactor returns the ramp multiplier, defaulting to
+// DefaultSearchGrowth.
+func (s CapacitySearch) GrowthFactor() float64 {
+	if s.Growth == 0 {
+		return DefaultSearchGrowth
+	}
+	return s.Growth
+}
+
+// PrecisionFraction returns the bisection precision, defaulting to
+// DefaultSearchPrecision.
+func (s CapacitySearch) PrecisionFraction() float64 {
+	if s.Precision == 0 {
+		return DefaultSearchPrecision
+	}
+	return s.Precision
+}
+
+// check validates the search, reporting problems under the "search" field.
+func (s CapacitySearch) check() []FieldError {
+	var problems []FieldError
+	add := func(field string, format string, args ...any) {
+		problems = append(problems, FieldError{Field: "search." + field, Err: fmt.Errorf(format, args...)})
+	}
+	if s.StartRate <= 0 {
+		add("start_rate", "start_rate must be greater than 0")
+	}
+	if s.MaxRate < 0 {
+		add("max_rate", "max_rate must not be negative")
+	} else if s.MaxRate > 0 && s.MaxRate < s.StartRate {
+		add("max_rate", "max_rate must not be below start_rate")
+	}
+	if s.StepDuration != "" {
+		if d, err := time.ParseDuration(s.StepDuration); err != nil || d <= 0 {
+			add("step_duration", "invalid step duration %q", s.StepDuration)
+		}
+	}
+	if s.Growth != 0 && s.Growth <= 1 {
+		add("growth", "growth must be greater than 1")
+	}
+	if s.Precision < 0 || s.Precision >= 1 {
+		add("precision", "precision must be between 0 and 1")
+	}
+	if s.MaxSteps < 0 {
+		add("max_steps", "max_steps must not be negative")
+	}
+	if s.SLO.IsZero() {
+		add("slo", "a capacity search needs at least one SLO threshold")
+	}
+	problems = append(problems, s.SLO.check("search.slo.")...)
+	return problems
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Thresholds are pass/fail limits on a run's results, such as the latency
+// and error budget of a service level objective. Unset limits are not
+// checked.
+type Thresholds struct {
+	LatencyAvg string `json:"latency_avg,omitempty"` // Durations such as "200ms"
+	LatencyP50 string `json:"latency_p50,omitempty"`
+	LatencyP95 string `json:"latency_p95,omitempty"`
+	LatencyP99 string `json:"latency_p99,omitempty"`
+	// ErrorRate is the highest error rate allowed, in percent. Zero allows
+	// no errors; leave it out to not check errors.
+	ErrorRate *float64 `json:"error_rate,omitempty"`
+}
+
+// LatencyLimit is a parsed latency threshold.
+type LatencyLimit struct {
+	Name       string        // avg, p50, p95 or p99
+	Percentile float64       // 0 for the average
+	Max        time.Duration // Highest latency allowed
+}
+
+// LatencyLimits returns the latency thresholds that are set. Unparsable
+// limits are skipped; Check reports them.
+func (t Thresholds) LatencyLimits() []LatencyLimit {
+	var limits []LatencyLimit
+	for _, l := range []struct {
+		name       string
+		percentile float64
+		value      string
+	}{
+		{"avg", 0, t.LatencyAvg},
+		{"p50", 50, t.LatencyP50},
+		{"p95", 95, t.LatencyP95},
+		{"p99", 99, t.LatencyP99},
+	} {
+		if d, err := time.ParseDuration(l.value); err == nil {
+			limits = append(limits, LatencyLimit{Name: l.name, Percentile: l.percentile, Max: d})
+		}
+	}
+	return limits
+}
+
+// IsZero reports whether no threshold is set.
+func (t Thresholds) IsZero() bool {
+	return t.LatencyAvg == "" && t.LatencyP50 == "" && t.LatencyP95 == "" && t.LatencyP99 == "" && t.ErrorRate == nil
+}
+
+// String describes the thresholds, such as "p99 < 200ms, errors <= 1%".
+func (t Thresholds) String() string {
+	var parts []string
+	for _, l := range t.LatencyLimits() {
+		parts = append(parts, fmt.Sprintf("%s < %s", l.Name, l.Max))
+	}
+	if t.ErrorRate != nil {
+		parts = append(parts, fmt.Sprintf("errors <= %g%%", *t.ErrorRate))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// check validates the thresholds, naming fields under prefix.
+func (t Thresholds) check(prefix string) []FieldError {
+	var problems []FieldError
+	for _, l := range []struct{ field, value string }{
+		{"latency_avg", t.LatencyAvg},
+		{"latency_p50", t.LatencyP50},
+		{"latency_p95", t.LatencyP95},
+		{"latency_p99", t.LatencyP99},
+	} {
+		if l.value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(l.value); err != nil || d <= 0 {
+			problems = append(problems, FieldError{Field: prefix + l.field, Err: fmt.Errorf("invalid latency threshold %q", l.value)})
+		}
+	}
+	if t.ErrorRate != nil && (*t.ErrorRate < 0 || *t.ErrorRate > 100) {
+		problems = append(problems, FieldError{Field: prefix + "error_rate", Err: fmt.Errorf("error rate threshold must be between 0 and 100")})
+	}
+	return problems
+}
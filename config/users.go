@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Think time distributions.
+const (
+	ThinkConstant    = "constant"
+	ThinkUniform     = "uniform"
+	ThinkNormal      = "normal"
+	ThinkExponential = "exponential"
+)
+
+// UserModel runs the benchmark as a closed model of concurrent virtual users.
+// Each user sends one request (picked from the mix, if any), then waits for
+// its think time or until its next paced iteration before sending the next.
+type UserModel struct {
+	Count     int        `json:"count"`
+	ThinkTime *ThinkTime `json:"think_time,omitempty"`
+	// Pacing is the fixed interval between the starts of a user's iterations.
+	// An iteration that takes longer is followed by the next one at once.
+	Pacing string `json:"pacing,omitempty"`
+}
+
+// ThinkTime is the distribution of the pause after each iteration. Durations
+// use Go syntax such as "1.5s".
+type ThinkTime struct {
+	Distribution string `json:"distribution,omitempty"` // One of the Think* constants; defaults to constant
+	Mean         string `json:"mean,omitempty"`         // constant, normal and exponential
+	StdDev       string `json:"stddev,omitempty"`       // normal
+	Min          string `json:"min,omitempty"`          // uniform
+	Max          string `json:"max,omitempty"`          // uniform
+}
+
+// ThinkTimeParams holds the parsed durations of a think time.
+type ThinkTimeParams struct {
+	Distribution           string
+	Mean, StdDev, Min, Max time.Duration
+}
+
+// Params parses the think time's durations and checks they fit its
+// distribution. Problems are reported against fields under prefix.
+func (t ThinkTime) Params(prefix string) (ThinkTimeParams, []FieldError) {
+	var problems []FieldError
+	p := ThinkTimeParams{Distribution: strings.ToLower(t.Distribution)}
+	if p.Distribution == "" {
+		p.Distribution = ThinkConstant
+	}
+	parse := func(field, value string, required bool) time.Duration {
+		if value == "" {
+			if required {
+				problems = append(problems, FieldError{Field: prefix + field, Err: fmt.Errorf("%s think time needs %s", p.Distribution, field)})
+			}
+			return 0
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			problems = append(problems, FieldError{Field: prefix + field, Err: fmt.Errorf("invalid think time %s %q", field, value)})
+		}
+		return d
+	}
+
+	switch p.Distribution {
+	case ThinkConstant, ThinkExponential:
+		p.Mean = parse("mean", t.Mean, true)
+	case ThinkNormal:
+		p.Mean = parse("mean", t.Mean, true)
+		p.StdDev = parse("stddev", t.StdDev, true)
+	case ThinkUniform:
+		p.Min = parse("min", t.Min, true)
+		p.Max = parse("max", t.Max, true)
+		if p.Max < p.Min {
+			problems = append(problems, FieldError{Field: prefix + "max", Err: fmt.Errorf("think time max must not be less than min")})
+		}
+	default:
+		problems = append(problems, FieldError{Field: prefix + "distribution", Err: fmt.Errorf("unknown think time distribution %q (use %s, %s, %s or %s)",
+			t.Distribution, ThinkConstant, ThinkUniform, ThinkNormal, ThinkExponential)})
+	}
+	return p, problems
+}
+
+// String describes the think time, such as "normal(2s ± 500ms)".
+func (t ThinkTime) String() string {
+	p, problems := t.Params("")
+	if len(problems) > 0 {
+		return "invalid"
+	}
+	switch p.Distribution {
+	case ThinkUniform:
+		return fmt.Sprintf("uniform(%s–%s)", p.Min, p.Max)
+	case ThinkNormal:
+		return fmt.Sprintf("normal(%s ± %s)", p.Mean, p.StdDev)
+	case ThinkExponential:
+		return fmt.Sprintf("exponential(mean %s)", p.Mean)
+	}
+	return p.Mean.String()
+}
+
+// check validates the user model, reporting problems under the "users" field.
+func (u UserModel) check() []FieldError {
+	var problems []FieldError
+	if u.Count <= 0 {
+		problems = append(problems, FieldError{Field: "users.count", Err: fmt.Errorf("virtual user count must be greater than 0")})
+	}
+	if u.ThinkTime != nil {
+		_, thinkProblems := u.ThinkTime.Params("users.think_time.")
+		problems = append(problems, thinkProblems...)
+	}
+	if u.Pacing != "" {
+		if d, err := time.ParseDuration(u.Pacing); err != nil || d <= 0 {
+			problems = append(problems, FieldError{Field: "users.pacing", Err: fmt.Errorf("invalid pacing %q", u.Pacing)})
+		}
+		if u.ThinkTime != nil {
+			problems = append(problems, FieldError{Field: "users.pacing", Err: fmt.Errorf("set either think_time or pacing, not both")})
+		}
+	}
+	return problems
+}
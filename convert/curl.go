@@ -0,0 +1,573 @@
+package convert
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Th4phat/go-wrk/config"
+)
+
+// Default load settings for imported requests, which carry no load information.
+const (
+	DefaultThreads     = 10
+	DefaultConnections = 50
+	DefaultDuration    = "30s"
+)
+
+// curl short options that take a value.
+var curlShortValue = map[byte]string{
+	'X': "--request", 'H': "--header", 'd': "--data", 'u': "--user",
+	'A': "--user-agent", 'b': "--cookie", 'e': "--referer", 'o': "--output",
+	'F': "--form", 'T': "--upload-file", 'm': "--max-time", 'x': "--proxy",
+	'E': "--cert", 'w': "--write-out", 'c': "--cookie-jar", 'r': "--range",
+}
+
+// curl short options without a value.
+var curlShortBool = map[byte]string{
+	's': "--silent", 'S': "--show-error", 'k': "--insecure", 'L': "--location",
+	'i': "--include", 'v': "--verbose", 'I': "--head", 'G': "--get",
+	'f': "--fail", 'N': "--no-buffer", '4': "--ipv4", '6': "--ipv6",
+	'g': "--globoff", 'O': "--remote-name", 'Z': "--parallel",
+}
+
+// Long options that take a value but have no effect on the benchmark.
+var curlIgnoredValue = map[string]bool{
+	"--output": true, "--max-time": true, "--connect-timeout": true, "--write-out": true,
+	"--cookie-jar": true, "--retry": true, "--retry-delay": true, "--retry-max-time": true,
+	"--trace": true, "--trace-ascii": true, "--stderr": true, "--dump-header": true,
+}
+
+// Long options that take a value and change the request in unsupported ways.
+var curlUnsupportedValue = map[string]bool{
+	"--upload-file": true, "--proxy": true,
+	"--cert": true, "--key": true, "--cacert": true, "--range": true, "--resolve": true,
+	"--connect-to": true, "--interface": true, "--unix-socket": true, "--proxy-user": true,
+	"--oauth2-bearer": true, "--config": true,
+}
+
+// Long options without a value that have no effect on the benchmark.
+var curlIgnoredBool = map[string]bool{
+	"--silent": true, "--show-error": true, "--include": true, "--verbose": true,
+	"--fail": true, "--no-buffer": true, "--globoff": true, "--progress-bar": true,
+	"--no-progress-meter": true, "--remote-name": true, "--ipv4": true, "--ipv6": true,
+	"--tcp-nodelay": true, "--no-keepalive": true, "--fail-with-body": true,
+}
+
+// Long options without a value that change the request in unsupported ways.
+var curlUnsupportedBool = map[string]bool{
+	"--location": true, "--http2": true, "--http2-prior-knowledge": true, "--http3": true,
+	"--http1.0": true, "--parallel": true, "--ntlm": true, "--negotiate": true,
+	"--digest": true, "--anyauth": true, "--location-trusted": true,
+}
+
+// ParseCurl converts a curl command line into a benchmark configuration using
+// the default load settings. Flags that cannot be represented are reported as
+// warnings rather than errors.
+func ParseCurl(command string) (config.BenchmarkConfig, []string, error) {
+	args, err := SplitShellWords(command)
+	if err != nil {
+		return config.BenchmarkConfig{}, nil, err
+	}
+	return ParseCurlArgs(args)
+}
+
+// ParseCurlArgs is like ParseCurl for a command line already split into words.
+func ParseCurlArgs(args []string) (config.BenchmarkConfig, []string, error) {
+	cfg := config.BenchmarkConfig{
+		Threads:     DefaultThreads,
+		Connections: DefaultConnections,
+		Duration:    DefaultDuration,
+	}
+	var warnings []string
+	warnf := func(format string, args ...any) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	if len(args) == 0 {
+		return cfg, nil, fmt.Errorf("empty curl command")
+	}
+	if args[0] == "curl" || strings.HasSuffix(args[0], "/curl") || args[0] == "curl.exe" {
+		args = args[1:]
+	}
+
+	var (
+		method    string
+		rawURL    string
+		dataParts []string
+		form      []config.FormField
+		forceGet  bool
+		basicAuth string
+		headers   = make(map[string]string)
+	)
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if len(arg) >= 2 && arg[0] == '-' && arg[1] != '-' {
+			// Expand clustered short options such as -sSLk or -XPOST in place
+			// and read them again. Option values are consumed below and never
+			// reach here, so a value such as "-5" is not taken for flags.
+			args = slices.Concat(args[:i], expandShortOptions(arg, warnf), args[i+1:])
+			i--
+			continue
+		}
+		if !strings.HasPrefix(arg, "--") {
+			if rawURL != "" {
+				warnf("additional URL %q ignored", arg)
+				continue
+			}
+			rawURL = arg
+			continue
+		}
+
+		// Options that take a value.
+		value := ""
+		takesValue := false
+		switch arg {
+		case "--request", "--header", "--data", "--data-raw", "--data-binary", "--data-ascii",
+			"--data-urlencode", "--json", "--form", "--form-string", "--user", "--user-agent", "--cookie", "--referer", "--url":
+			takesValue = true
+		default:
+			takesValue = curlIgnoredValue[arg] || curlUnsupportedValue[arg]
+		}
+		if takesValue {
+			if i+1 >= len(args) {
+				return cfg, warnings, fmt.Errorf("flag %s requires a value", arg)
+			}
+			i++
+			value = args[i]
+		}
+
+		switch arg {
+		case "--request":
+			method = strings.ToUpper(value)
+		case "--url":
+			rawURL = value
+		case "--header":
+			name, val, found := strings.Cut(value, ":")
+			if !found {
+				warnf("malformed header %q ignored", value)
+				continue
+			}
+			name = strings.TrimSpace(name)
+			val = strings.TrimSpace(val)
+			if val == "" {
+				// "Name:" removes a header in curl; nothing to send.
+				delete(headers, name)
+				continue
+			}
+			headers[name] = val
+		case "--data", "--data-ascii", "--data-binary":
+			data := value
+			if strings.HasPrefix(data, "@") {
+				content, err := os.ReadFile(strings.TrimPrefix(data, "@"))
+				if err != nil {
+					warnf("could not read data file %s: %v", data[1:], err)
+					continue
+				}
+				data = string(content)
+				if arg != "--data-binary" {
+					data = strings.NewReplacer("\r", "", "\n", "").Replace(data)
+				}
+			}
+			dataParts = append(dataParts, data)
+		case "--data-raw":
+			dataParts = append(dataParts, value)
+		case "--data-urlencode":
+			data, err := encodeDataURLEncode(value)
+			if err != nil {
+				warnf("%v", err)
+				continue
+			}
+			dataParts = append(dataParts, data)
+		case "--json":
+			dataParts = append(dataParts, value)
+			if _, ok := findHeader(headers, "Content-Type"); !ok {
+				headers["Content-Type"] = "application/json"
+			}
+			if _, ok := findHeader(headers, "Accept"); !ok {
+				headers["Accept"] = "application/json"
+			}
+		case "--form":
+			field, err := config.ParseFormField(value)
+			if err != nil {
+				warnf("%v; ignored", err)
+				continue
+			}
+			form = append(form, field)
+		case "--form-string":
+			name, val, found := strings.Cut(value, "=")
+			if !found {
+				warnf("malformed form field %q ignored", value)
+				continue
+			}
+			form = append(form, config.FormField{Name: name, Value: val})
+		case "--user":
+			basicAuth = value
+		case "--user-agent":
+			headers["User-Agent"] = value
+		case "--cookie":
+			if strings.Contains(value, "=") {
+				headers["Cookie"] = value
+			} else {
+				warnf("cookie file %q ignored; only inline cookies are supported", value)
+			}
+		case "--referer":
+			headers["Referer"] = value
+		case "--compressed":
+			if _, ok := findHeader(headers, "Accept-Encoding"); !ok {
+				headers["Accept-Encoding"] = "gzip, deflate, br"
+			}
+		case "--insecure":
+			insecure := true
+			cfg.Insecure = &insecure
+		case "--head":
+			method = "HEAD"
+		case "--get":
+			forceGet = true
+		default:
+			switch {
+			case curlIgnoredValue[arg] || curlIgnoredBool[arg]:
+			case curlUnsupportedValue[arg]:
+				warnf("unsupported flag %s %q ignored", arg, value)
+			case curlUnsupportedBool[arg]:
+				warnf("unsupported flag %s ignored", arg)
+			default:
+				warnf("unknown flag %s ignored", arg)
+			}
+		}
+	}
+
+	if rawURL == "" {
+		return cfg, warnings, fmt.Errorf("no URL found in curl command")
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+
+	if basicAuth != "" {
+		// An explicit Authorization header takes precedence over -u, as in curl.
+		if _, ok := findHeader(headers, "Authorization"); !ok {
+			headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(basicAuth))
+		}
+	}
+
+	payload := strings.Join(dataParts, "&")
+	if len(form) > 0 && payload != "" {
+		return cfg, warnings, fmt.Errorf("-F cannot be combined with -d or --json")
+	}
+	if forceGet && payload != "" {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return cfg, warnings, fmt.Errorf("invalid URL %q: %w", rawURL, err)
+		}
+		if u.RawQuery != "" {
+			u.RawQuery += "&"
+		}
+		u.RawQuery += payload
+		rawURL = u.String()
+		payload = ""
+	}
+
+	if method == "" {
+		method = "GET"
+		if payload != "" || len(form) > 0 {
+			method = "POST"
+		}
+	}
+	if len(form) > 0 {
+		// The encoder sets the multipart Content-Type with its boundary.
+		for name := range headers {
+			if strings.EqualFold(name, "Content-Type") {
+				delete(headers, name)
+			}
+		}
+		cfg.BodyType = config.BodyMultipart
+		cfg.Form = form
+	}
+	if payload != "" {
+		if _, ok := findHeader(headers, "Content-Type"); !ok {
+			// curl labels -d bodies as form data unless told otherwise.
+			headers["Content-Type"] = "application/x-www-form-urlencoded"
+		}
+	}
+
+	cfg.TargetURL = rawURL
+	cfg.Method = method
+	cfg.Payload = payload
+	if len(headers) > 0 {
+		cfg.Headers = headers
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, warnings, err
+	}
+	return cfg, warnings, nil
+}
+
+// expandShortOptions rewrites a cluster of short options, such as -sSLk or
+// -XPOST, as long options. A value attached to the last option follows it as
+// its own argument.
+func expandShortOptions(arg string, warnf func(format string, args ...any)) []string {
+	var expanded []string
+	for i := 1; i < len(arg); i++ {
+		c := arg[i]
+		if long, ok := curlShortValue[c]; ok {
+			expanded = append(expanded, long)
+			if i+1 < len(arg) {
+				expanded = append(expanded, arg[i+1:])
+			}
+			break
+		}
+		if long, ok := curlShortBool[c]; ok {
+			expanded = append(expanded, long)
+			continue
+		}
+		warnf("unsupported flag -%c ignored", c)
+	}
+	return expanded
+}
+
+// TestNameFromRequest derives a file-friendly test name such as
+// "post_api_users" from a method and URL.
+func TestNameFromRequest(method, rawURL string) string {
+	path := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		path = u.Path
+	}
+	var parts []string
+	for _, seg := range strings.Split(path, "/") {
+		seg = strings.Trim(seg, "{}:")
+		if seg != "" {
+			parts = append(parts, seg)
+		}
+	}
+	name := strings.ToLower(method)
+	if len(parts) == 0 {
+		name += "_root"
+	} else {
+		name += "_" + strings.Join(parts, "_")
+	}
+	return config.SanitizeFilename(name)
+}
+
+func findHeader(headers map[string]string, name string) (string, bool) {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// encodeDataURLEncode implements the content forms accepted by curl's
+// --data-urlencode: "content", "=content", "name=content", "@file" and "name@file".
+// Like curl, it splits at the first "=" and only then looks for "@".
+func encodeDataURLEncode(value string) (string, error) {
+	i := strings.IndexByte(value, '=')
+	if i < 0 {
+		i = strings.IndexByte(value, '@')
+	}
+	if i < 0 {
+		return url.QueryEscape(value), nil
+	}
+	name, content := value[:i], value[i+1:]
+	if value[i] == '@' {
+		data, err := os.ReadFile(content)
+		if err != nil {
+			return "", fmt.Errorf("could not read data file %s: %w", content, err)
+		}
+		content = string(data)
+	}
+	if name == "" {
+		return url.QueryEscape(content), nil
+	}
+	return name + "=" + url.QueryEscape(content), nil
+}
+
+// SplitShellWords splits a POSIX shell command line into words, handling
+// single quotes, double quotes, $'...' strings and backslash line continuations
+// as produced by "Copy as cURL" in browsers.
+func SplitShellWords(s string) ([]string, error) {
+	var words []string
+	var cur strings.Builder
+	inWord := false
+
+	flush := func() {
+		if inWord {
+			words = append(words, cur.String())
+			cur.Reset()
+			inWord = false
+		}
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\':
+			if i+1 < len(s) && s[i+1] == '\r' && i+2 < len(s) && s[i+2] == '\n' {
+				i += 2
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '\n' {
+				i++
+				continue
+			}
+			if i+1 < len(s) {
+				cur.WriteByte(s[i+1])
+				i++
+				inWord = true
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			flush()
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			cur.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '$' && i+1 < len(s) && s[i+1] == '\'':
+			j := i + 2
+			for ; j < len(s) && s[j] != '\''; j++ {
+				if s[j] != '\\' || j+1 >= len(s) {
+					cur.WriteByte(s[j])
+					continue
+				}
+				j++
+				switch s[j] {
+				case 'n':
+					cur.WriteByte('\n')
+				case 't':
+					cur.WriteByte('\t')
+				case 'r':
+					cur.WriteByte('\r')
+				case '0':
+					cur.WriteByte(0)
+				case 'x':
+					if j+2 < len(s) {
+						var b byte
+						if _, err := fmt.Sscanf(s[j+1:j+3], "%02x", &b); err == nil {
+							cur.WriteByte(b)
+							j += 2
+							continue
+						}
+					}
+					cur.WriteString("\\x")
+				case 'u':
+					if j+4 < len(s) {
+						var r rune
+						if _, err := fmt.Sscanf(s[j+1:j+5], "%04x", &r); err == nil {
+							cur.WriteRune(r)
+							j += 4
+							continue
+						}
+					}
+					cur.WriteString("\\u")
+				default:
+					cur.WriteByte(s[j])
+				}
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated $' quote")
+			}
+			i = j
+			inWord = true
+		case c == '"':
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' && j+1 < len(s) && strings.IndexByte("\\\"$`\n", s[j+1]) >= 0 {
+					j++
+					if s[j] == '\n' {
+						continue
+					}
+				}
+				cur.WriteByte(s[j])
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+			i = j
+			inWord = true
+		default:
+			cur.WriteByte(c)
+			inWord = true
+		}
+	}
+	flush()
+	return words, nil
+}
+
+// FormatCurl returns a curl command line equivalent to the request described
+// by cfg, with one option per line.
+func FormatCurl(cfg config.BenchmarkConfig) string {
+	method := cfg.Method
+	if method == "" {
+		method = "GET"
+	}
+
+	parts := []string{"curl " + shellQuote(cfg.TargetURL)}
+	switch {
+	case strings.EqualFold(method, "HEAD"):
+		// curl -X HEAD would wait for a response body.
+		parts = append(parts, "--head")
+	case method != "GET":
+		parts = append(parts, "-X "+shellQuote(method))
+	}
+
+	names := make([]string, 0, len(cfg.Headers))
+	for name := range cfg.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// curl sets the multipart Content-Type and boundary itself.
+	multipart := strings.EqualFold(cfg.BodyType, config.BodyMultipart) && len(cfg.Form) > 0
+	for _, name := range names {
+		if multipart && strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+		parts = append(parts, "-H "+shellQuote(name+": "+cfg.Headers[name]))
+	}
+
+	if cfg.HasBody() {
+		if _, ok := findHeader(cfg.Headers, "Content-Type"); !ok && !multipart {
+			parts = append(parts, "-H "+shellQuote("Content-Type: "+config.BodyContentType(cfg.BodyType)))
+		}
+		if multipart {
+			for _, f := range cfg.Form {
+				if f.File == "" && f.ContentType == "" {
+					parts = append(parts, "--form-string "+shellQuote(f.Name+"="+f.Value))
+				} else {
+					parts = append(parts, "-F "+shellQuote(f.String()))
+				}
+			}
+		} else if len(cfg.Form) > 0 {
+			for _, f := range cfg.Form {
+				parts = append(parts, "--data-urlencode "+shellQuote(f.Name+"="+f.Value))
+			}
+		} else if cfg.PayloadFile != "" {
+			parts = append(parts, "--data-binary "+shellQuote("@"+cfg.PayloadFile))
+		} else {
+			parts = append(parts, "--data-raw "+shellQuote(cfg.Payload))
+		}
+	}
+	if cfg.SkipVerify() {
+		parts = append(parts, "-k")
+	}
+	return strings.Join(parts, " \\\n  ")
+}
+
+// shellQuote quotes s for a POSIX shell, leaving simple words unquoted.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@%+,", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
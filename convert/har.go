@@ -0,0 +1,219 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/Th4phat/go-wrk/config"
+)
+
+// Headers recorded by browsers that must not be replayed verbatim.
+var harSkippedHeaders = map[string]bool{
+	"host": true, "content-length": true, "connection": true, "keep-alive": true,
+	"transfer-encoding": true, "upgrade": true, "proxy-connection": true, "te": true,
+}
+
+type harFile struct {
+	Log struct {
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harEntry struct {
+	Request struct {
+		Method   string         `json:"method"`
+		URL      string         `json:"url"`
+		Headers  []harNameValue `json:"headers"`
+		PostData *struct {
+			MimeType string         `json:"mimeType"`
+			Text     string         `json:"text"`
+			Params   []harNameValue `json:"params"`
+		} `json:"postData"`
+	} `json:"request"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARImport is the result of converting a HAR capture.
+type HARImport struct {
+	Host     string // The host all converted requests target
+	Requests []config.RequestSpec
+	Warnings []string
+}
+
+// ParseHAR converts the entries of a HAR capture into distinct requests
+// weighted by how often they were recorded. Only requests to a single host can
+// share a benchmark, so entries for other hosts are dropped with a warning;
+// host selects the host to keep and defaults to the most frequent one.
+func ParseHAR(data []byte, host string) (*HARImport, error) {
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("parsing HAR file: %w", err)
+	}
+	if len(har.Log.Entries) == 0 {
+		return nil, fmt.Errorf("HAR file has no entries")
+	}
+
+	result := &HARImport{}
+	hostCounts := make(map[string]int)
+	for _, e := range har.Log.Entries {
+		if u, err := url.Parse(e.Request.URL); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+			hostCounts[u.Scheme+"://"+u.Host]++
+		}
+	}
+	if host == "" {
+		best := 0
+		for h, n := range hostCounts {
+			if n > best || (n == best && h < host) {
+				host, best = h, n
+			}
+		}
+	} else if !strings.Contains(host, "://") {
+		// Match the host on either scheme.
+		for h := range hostCounts {
+			if strings.HasSuffix(h, "://"+host) {
+				host = h
+				break
+			}
+		}
+	}
+	if hostCounts[host] == 0 {
+		return nil, fmt.Errorf("no HTTP requests to %q in HAR file", host)
+	}
+	result.Host = host
+
+	index := make(map[string]int)
+	dropped := 0
+	for _, e := range har.Log.Entries {
+		u, err := url.Parse(e.Request.URL)
+		if err != nil || u.Scheme+"://"+u.Host != host {
+			dropped++
+			continue
+		}
+		u.Fragment = ""
+
+		spec := config.RequestSpec{
+			Weight: 1,
+			Method: strings.ToUpper(e.Request.Method),
+			URL:    u.String(),
+		}
+		headers := make(map[string]string)
+		for _, h := range e.Request.Headers {
+			name := h.Name
+			if strings.HasPrefix(name, ":") || harSkippedHeaders[strings.ToLower(name)] {
+				continue
+			}
+			headers[name] = h.Value
+		}
+		if pd := e.Request.PostData; pd != nil {
+			spec.Payload = pd.Text
+			if spec.Payload == "" && len(pd.Params) > 0 {
+				form := url.Values{}
+				for _, p := range pd.Params {
+					form.Add(p.Name, p.Value)
+				}
+				spec.Payload = form.Encode()
+			}
+			if _, ok := findHeader(headers, "Content-Type"); !ok && pd.MimeType != "" {
+				headers["Content-Type"] = pd.MimeType
+			}
+		}
+		if len(headers) > 0 {
+			spec.Headers = headers
+		}
+
+		key := spec.Method + " " + spec.URL + "\n" + spec.Payload
+		if i, ok := index[key]; ok {
+			result.Requests[i].Weight++
+			continue
+		}
+		index[key] = len(result.Requests)
+		result.Requests = append(result.Requests, spec)
+	}
+	if dropped > 0 {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("%d entries for hosts other than %s were dropped", dropped, host))
+	}
+	sort.SliceStable(result.Requests, func(i, j int) bool {
+		return result.Requests[i].Weight > result.Requests[j].Weight
+	})
+	return result, nil
+}
+
+// Mix returns a single benchmark config replaying the captured requests with
+// their recorded frequencies as weights. Headers common to every request are
+// hoisted into the config's headers.
+func (h *HARImport) Mix() config.BenchmarkConfig {
+	first := h.Requests[0]
+	cfg := config.BenchmarkConfig{
+		TargetURL:   first.URL,
+		Method:      first.Method,
+		Payload:     first.Payload,
+		Threads:     DefaultThreads,
+		Connections: DefaultConnections,
+		Duration:    DefaultDuration,
+	}
+
+	common := make(map[string]string)
+	for k, v := range first.Headers {
+		common[k] = v
+	}
+	for _, spec := range h.Requests[1:] {
+		for k, v := range common {
+			if spec.Headers[k] != v {
+				delete(common, k)
+			}
+		}
+	}
+
+	for _, spec := range h.Requests {
+		specHeaders := make(map[string]string)
+		for k, v := range spec.Headers {
+			if _, ok := common[k]; !ok {
+				specHeaders[k] = v
+			}
+		}
+		if len(specHeaders) == 0 {
+			specHeaders = nil
+		}
+		cfg.Mix = append(cfg.Mix, config.RequestSpec{
+			Weight: spec.Weight, Method: spec.Method, URL: spec.URL, Headers: specHeaders, Payload: spec.Payload,
+		})
+	}
+	if len(common) > 0 {
+		cfg.Headers = common
+	}
+	return cfg
+}
+
+// Collection returns one test per distinct captured request.
+func (h *HARImport) Collection(name string) config.TestCollection {
+	collection := config.TestCollection{Name: config.SanitizeFilename(name)}
+	usedNames := make(map[string]int)
+	for _, spec := range h.Requests {
+		testName := TestNameFromRequest(spec.Method, spec.URL)
+		usedNames[testName]++
+		if n := usedNames[testName]; n > 1 {
+			testName = fmt.Sprintf("%s_%d", testName, n)
+		}
+		collection.Tests = append(collection.Tests, config.Test{
+			Name: testName,
+			Config: config.BenchmarkConfig{
+				TargetURL:   spec.URL,
+				Method:      spec.Method,
+				Headers:     spec.Headers,
+				Payload:     spec.Payload,
+				Threads:     DefaultThreads,
+				Connections: DefaultConnections,
+				Duration:    DefaultDuration,
+			},
+		})
+	}
+	return collection
+}
@@ -0,0 +1,586 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Th4phat/go-wrk/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIMethods lists the operation keys of a path item, in output order.
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// maxSchemaDepth bounds example synthesis for deeply nested or recursive schemas.
+const maxSchemaDepth = 8
+
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// SkippedOperation is an operation that could not be turned into a test.
+type SkippedOperation struct {
+	Method string
+	Path   string
+	Reason string
+}
+
+// OpenAPIImport is the result of converting an OpenAPI document.
+type OpenAPIImport struct {
+	Title   string
+	Tests   []config.Test
+	Skipped []SkippedOperation
+}
+
+type openAPIDoc struct {
+	root map[string]any
+}
+
+// ParseOpenAPI converts an OpenAPI 3 document (YAML or JSON) into one test
+// per operation. baseURL overrides the scheme and host of the document's first
+// server URL, and its path if baseURL has one.
+func ParseOpenAPI(data []byte, baseURL string) (*OpenAPIImport, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing OpenAPI document: %w", err)
+	}
+	root, ok := config.NormalizeYAML(raw).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("OpenAPI document is not an object")
+	}
+	version, _ := root["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		if _, isSwagger := root["swagger"]; isSwagger {
+			return nil, fmt.Errorf("swagger 2.0 documents are not supported; convert to OpenAPI 3 first")
+		}
+		return nil, fmt.Errorf("unsupported or missing openapi version %q", version)
+	}
+	doc := &openAPIDoc{root: root}
+
+	serverURL, err := doc.serverURL()
+	if err != nil {
+		return nil, err
+	}
+	baseURL, err = requestBaseURL(baseURL, serverURL)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &OpenAPIImport{}
+	if info, ok := root["info"].(map[string]any); ok {
+		result.Title, _ = info["title"].(string)
+	}
+
+	paths, _ := root["paths"].(map[string]any)
+	pathKeys := make([]string, 0, len(paths))
+	for p := range paths {
+		pathKeys = append(pathKeys, p)
+	}
+	sort.Strings(pathKeys)
+
+	usedNames := make(map[string]int)
+	for _, path := range pathKeys {
+		item, ok := doc.resolve(paths[path]).(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, method := range openAPIMethods {
+			op, ok := item[method].(map[string]any)
+			if !ok {
+				continue
+			}
+			cfg, err := doc.buildOperation(baseURL, path, method, item, op)
+			if err != nil {
+				result.Skipped = append(result.Skipped, SkippedOperation{
+					Method: strings.ToUpper(method), Path: path, Reason: err.Error(),
+				})
+				continue
+			}
+
+			name := TestNameFromRequest(method, path)
+			if opID, ok := op["operationId"].(string); ok && opID != "" {
+				name = config.SanitizeFilename(opID)
+			}
+			usedNames[name]++
+			if n := usedNames[name]; n > 1 {
+				name = fmt.Sprintf("%s_%d", name, n)
+			}
+			result.Tests = append(result.Tests, config.Test{Name: name, Config: cfg})
+		}
+	}
+	return result, nil
+}
+
+// serverURL returns the first server URL with its variables replaced by
+// their defaults. It fails on a variable without a string default.
+func (d *openAPIDoc) serverURL() (string, error) {
+	servers, _ := d.root["servers"].([]any)
+	if len(servers) == 0 {
+		return "", nil
+	}
+	server, _ := servers[0].(map[string]any)
+	u, _ := server["url"].(string)
+	if vars, ok := server["variables"].(map[string]any); ok {
+		for name, v := range vars {
+			vm, _ := v.(map[string]any)
+			def, ok := vm["default"].(string)
+			if !ok {
+				return "", fmt.Errorf("server variable %q has no string default", name)
+			}
+			u = strings.ReplaceAll(u, "{"+name+"}", def)
+		}
+	}
+	return u, nil
+}
+
+// requestBaseURL returns the URL operation paths are appended to. The server
+// URL may be relative, such as "/api/v1". A base URL replaces its scheme and
+// host, and its path too if the base URL has one.
+func requestBaseURL(baseURL, serverURL string) (string, error) {
+	server, err := url.Parse(serverURL)
+	if err != nil {
+		server = &url.URL{}
+	}
+	if baseURL == "" {
+		if server.Scheme == "" || server.Host == "" {
+			return "", fmt.Errorf("the document has no absolute server URL; pass a base URL")
+		}
+		return strings.TrimRight(serverURL, "/"), nil
+	}
+
+	base, err := url.Parse(baseURL)
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return "", fmt.Errorf("base URL %q must be absolute, e.g. http://localhost:8080", baseURL)
+	}
+	if strings.Trim(base.Path, "/") == "" {
+		base.Path = server.Path
+		base.RawPath = server.RawPath
+	}
+	return strings.TrimRight(base.String(), "/"), nil
+}
+
+func (d *openAPIDoc) buildOperation(baseURL, path, method string, item, op map[string]any) (config.BenchmarkConfig, error) {
+	cfg := config.BenchmarkConfig{
+		Method:      strings.ToUpper(method),
+		Threads:     DefaultThreads,
+		Connections: DefaultConnections,
+		Duration:    DefaultDuration,
+	}
+
+	// Operation parameters override path-level ones with the same name and location.
+	params := make(map[string]map[string]any)
+	var order []string
+	for _, list := range []any{item["parameters"], op["parameters"]} {
+		entries, _ := list.([]any)
+		for _, entry := range entries {
+			p, ok := d.resolve(entry).(map[string]any)
+			if !ok {
+				continue
+			}
+			key := fmt.Sprint(p["in"]) + ":" + fmt.Sprint(p["name"])
+			if _, seen := params[key]; !seen {
+				order = append(order, key)
+			}
+			params[key] = p
+		}
+	}
+
+	pathValues := make(map[string]string)
+	query := url.Values{}
+	headers := make(map[string]string)
+	for _, key := range order {
+		p := params[key]
+		name, _ := p["name"].(string)
+		in, _ := p["in"].(string)
+		required, _ := p["required"].(bool)
+		value, ok := d.parameterExample(p)
+		if !ok {
+			if required || in == "path" {
+				return cfg, fmt.Errorf("no example or schema for required %s parameter %q", in, name)
+			}
+			continue
+		}
+		switch in {
+		case "path":
+			pathValues[name] = value
+		case "query":
+			if required || hasExplicitExample(p) {
+				query.Add(name, value)
+			}
+		case "header":
+			if required || hasExplicitExample(p) {
+				headers[name] = value
+			}
+		case "cookie":
+			if required {
+				return cfg, fmt.Errorf("cookie parameter %q is not supported", name)
+			}
+		}
+	}
+
+	var missing []string
+	resolvedPath := pathParamPattern.ReplaceAllStringFunc(path, func(m string) string {
+		name := m[1 : len(m)-1]
+		v, ok := pathValues[name]
+		if !ok {
+			missing = append(missing, name)
+			return m
+		}
+		return url.PathEscape(v)
+	})
+	if len(missing) > 0 {
+		return cfg, fmt.Errorf("path parameters %s are not declared", strings.Join(missing, ", "))
+	}
+
+	cfg.TargetURL = baseURL + resolvedPath
+	if len(query) > 0 {
+		cfg.TargetURL += "?" + query.Encode()
+	}
+
+	if body, ok := d.resolve(op["requestBody"]).(map[string]any); ok {
+		required, _ := body["required"].(bool)
+		contentType, payload, err := d.bodyExample(body)
+		if err != nil {
+			if required {
+				return cfg, err
+			}
+		} else {
+			headers["Content-Type"] = contentType
+			cfg.Payload = payload
+		}
+	}
+
+	if len(headers) > 0 {
+		cfg.Headers = headers
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+func hasExplicitExample(p map[string]any) bool {
+	if _, ok := p["example"]; ok {
+		return true
+	}
+	if ex, ok := p["examples"].(map[string]any); ok && len(ex) > 0 {
+		return true
+	}
+	if schema, ok := p["schema"].(map[string]any); ok {
+		_, hasExample := schema["example"]
+		_, hasDefault := schema["default"]
+		return hasExample || hasDefault
+	}
+	return false
+}
+
+func (d *openAPIDoc) parameterExample(p map[string]any) (string, bool) {
+	if ex, ok := p["example"]; ok {
+		return scalarString(ex), true
+	}
+	if v, ok := d.firstExample(p["examples"]); ok {
+		return scalarString(v), true
+	}
+	if schema, ok := p["schema"]; ok {
+		v, err := d.schemaExample(schema, 0, map[string]bool{})
+		if err == nil {
+			return scalarString(v), true
+		}
+	}
+	return "", false
+}
+
+// bodyExample picks the most suitable media type of a request body and
+// returns its content type and an example payload.
+func (d *openAPIDoc) bodyExample(body map[string]any) (string, string, error) {
+	content, _ := body["content"].(map[string]any)
+	if len(content) == 0 {
+		return "", "", fmt.Errorf("request body has no content")
+	}
+	types := make([]string, 0, len(content))
+	for t := range content {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return mediaRank(types[i]) < mediaRank(types[j]) })
+
+	var lastErr error
+	for _, mediaType := range types {
+		media, _ := content[mediaType].(map[string]any)
+		base := strings.TrimSpace(strings.SplitN(mediaType, ";", 2)[0])
+
+		var value any
+		found := false
+		if ex, ok := media["example"]; ok {
+			value, found = ex, true
+		} else if ex, ok := d.firstExample(media["examples"]); ok {
+			value, found = ex, true
+		} else if schema, ok := media["schema"]; ok {
+			v, err := d.schemaExample(schema, 0, map[string]bool{})
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			value, found = v, true
+		}
+		if !found {
+			lastErr = fmt.Errorf("no example or schema for %s body", mediaType)
+			continue
+		}
+
+		switch {
+		case base == "application/json" || strings.HasSuffix(base, "+json"):
+			if s, ok := value.(string); ok && json.Valid([]byte(s)) {
+				return mediaType, s, nil
+			}
+			data, err := json.Marshal(value)
+			if err != nil {
+				lastErr = fmt.Errorf("encoding JSON example: %w", err)
+				continue
+			}
+			return mediaType, string(data), nil
+		case base == "application/x-www-form-urlencoded":
+			obj, ok := value.(map[string]any)
+			if !ok {
+				lastErr = fmt.Errorf("form body example is not an object")
+				continue
+			}
+			form := url.Values{}
+			for k, v := range obj {
+				form.Set(k, scalarString(v))
+			}
+			return mediaType, form.Encode(), nil
+		default:
+			if s, ok := value.(string); ok {
+				return mediaType, s, nil
+			}
+			lastErr = fmt.Errorf("cannot synthesize a %s body without a literal example", mediaType)
+		}
+	}
+	return "", "", lastErr
+}
+
+func mediaRank(mediaType string) int {
+	base := strings.TrimSpace(strings.SplitN(mediaType, ";", 2)[0])
+	switch {
+	case base == "application/json":
+		return 0
+	case strings.HasSuffix(base, "+json"):
+		return 1
+	case base == "application/x-www-form-urlencoded":
+		return 2
+	case strings.HasPrefix(base, "text/"):
+		return 3
+	default:
+		return 4
+	}
+}
+
+func (d *openAPIDoc) firstExample(examples any) (any, bool) {
+	m, ok := examples.(map[string]any)
+	if !ok || len(m) == 0 {
+		return nil, false
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	ex, ok := d.resolve(m[keys[0]]).(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	v, ok := ex["value"]
+	return v, ok
+}
+
+// schemaExample synthesizes an example value from a JSON schema.
+func (d *openAPIDoc) schemaExample(node any, depth int, seen map[string]bool) (any, error) {
+	if depth > maxSchemaDepth {
+		return nil, nil
+	}
+	schema, ok := node.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid schema")
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		if seen[ref] {
+			return nil, nil // Recursive schema: stop here
+		}
+		target, err := d.lookup(ref)
+		if err != nil {
+			return nil, err
+		}
+		seen[ref] = true
+		defer delete(seen, ref)
+		return d.schemaExample(target, depth+1, seen)
+	}
+
+	for _, k := range []string{"example", "default", "const"} {
+		if v, ok := schema[k]; ok {
+			return v, nil
+		}
+	}
+	if examples, ok := schema["examples"].([]any); ok && len(examples) > 0 {
+		return examples[0], nil
+	}
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {
+		return enum[0], nil
+	}
+
+	if all, ok := schema["allOf"].([]any); ok {
+		merged := map[string]any{}
+		for _, sub := range all {
+			v, err := d.schemaExample(sub, depth+1, seen)
+			if err != nil {
+				return nil, err
+			}
+			if obj, ok := v.(map[string]any); ok {
+				for k, val := range obj {
+					merged[k] = val
+				}
+			} else if v != nil {
+				return v, nil
+			}
+		}
+		return merged, nil
+	}
+	for _, k := range []string{"oneOf", "anyOf"} {
+		if alts, ok := schema[k].([]any); ok && len(alts) > 0 {
+			return d.schemaExample(alts[0], depth+1, seen)
+		}
+	}
+
+	typ := schema["type"]
+	if types, ok := typ.([]any); ok {
+		// OpenAPI 3.1 allows a list of types; use the first non-null one.
+		typ = nil
+		for _, t := range types {
+			if t != "null" {
+				typ = t
+				break
+			}
+		}
+	}
+	if typ == nil {
+		if _, ok := schema["properties"]; ok {
+			typ = "object"
+		} else if _, ok := schema["items"]; ok {
+			typ = "array"
+		}
+	}
+
+	switch typ {
+	case "object":
+		obj := map[string]any{}
+		props, _ := schema["properties"].(map[string]any)
+		for name, prop := range props {
+			v, err := d.schemaExample(prop, depth+1, seen)
+			if err != nil {
+				return nil, err
+			}
+			if v != nil {
+				obj[name] = v
+			}
+		}
+		return obj, nil
+	case "array":
+		items, ok := schema["items"]
+		if !ok {
+			return []any{}, nil
+		}
+		v, err := d.schemaExample(items, depth+1, seen)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			return []any{}, nil
+		}
+		return []any{v}, nil
+	case "string":
+		switch schema["format"] {
+		case "date-time":
+			return "2024-01-01T00:00:00Z", nil
+		case "date":
+			return "2024-01-01", nil
+		case "email":
+			return "user@example.com", nil
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000001", nil
+		case "uri", "url":
+			return "https://example.com", nil
+		case "ipv4":
+			return "192.0.2.1", nil
+		case "byte":
+			return "ZXhhbXBsZQ==", nil
+		case "binary":
+			return nil, fmt.Errorf("cannot synthesize binary content")
+		}
+		return "string", nil
+	case "integer", "number":
+		if v, ok := schema["minimum"]; ok {
+			return v, nil
+		}
+		return 1, nil
+	case "boolean":
+		return true, nil
+	}
+	return nil, fmt.Errorf("schema has no type or example")
+}
+
+// resolve follows a $ref if node is a reference object.
+func (d *openAPIDoc) resolve(node any) any {
+	for i := 0; i < 16; i++ {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return node
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return node
+		}
+		target, err := d.lookup(ref)
+		if err != nil {
+			return nil
+		}
+		node = target
+	}
+	return nil
+}
+
+// lookup resolves a local JSON pointer such as "#/components/schemas/User".
+func (d *openAPIDoc) lookup(ref string) (any, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("external reference %q is not supported", ref)
+	}
+	var node any = d.root
+	for _, part := range strings.Split(ref[2:], "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unresolvable reference %q", ref)
+		}
+		node, ok = m[part]
+		if !ok {
+			return nil, fmt.Errorf("unresolvable reference %q", ref)
+		}
+	}
+	return node, nil
+}
+
+func scalarString(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case nil:
+		return ""
+	case map[string]any, []any:
+		data, err := json.Marshal(t)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,383 @@
+package convert
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Th4phat/go-wrk/config"
+)
+
+var postmanVarPattern = regexp.MustCompile(`\{\{\s*([^{}]+?)\s*\}\}`)
+
+type postmanCollection struct {
+	Info struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema"`
+	} `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Variable []postmanKeyValue `json:"variable"`
+	Auth     *postmanAuth      `json:"auth"`
+}
+
+type postmanItem struct {
+	Name     string            `json:"name"`
+	Item     []postmanItem     `json:"item"` // Set for folders
+	Request  *postmanRequest   `json:"request"`
+	Variable []postmanKeyValue `json:"variable"`
+	Auth     *postmanAuth      `json:"auth"`
+}
+
+type postmanRequest struct {
+	Method string            `json:"method"`
+	Header []postmanKeyValue `json:"header"`
+	URL    postmanURL        `json:"url"`
+	Body   *postmanBody      `json:"body"`
+	Auth   *postmanAuth      `json:"auth"`
+}
+
+type postmanKeyValue struct {
+	Key      string `json:"key"`
+	Value    any    `json:"value"`
+	Disabled bool   `json:"disabled"`
+	Enabled  *bool  `json:"enabled"` // Used by environment files
+	Type     string `json:"type"`
+
+	// Set on form-data file fields.
+	Src         any    `json:"src"`
+	ContentType string `json:"contentType"`
+}
+
+func (kv postmanKeyValue) active() bool {
+	return !kv.Disabled && (kv.Enabled == nil || *kv.Enabled)
+}
+
+func (kv postmanKeyValue) value() string {
+	if kv.Value == nil {
+		return ""
+	}
+	return scalarString(kv.Value)
+}
+
+// postmanURL accepts both the string and the object form of a request URL.
+type postmanURL struct {
+	Raw   string            `json:"raw"`
+	Query []postmanKeyValue `json:"query"`
+}
+
+func (u *postmanURL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		u.Raw = raw
+		return nil
+	}
+	type plain postmanURL
+	return json.Unmarshal(data, (*plain)(u))
+}
+
+type postmanBody struct {
+	Mode       string            `json:"mode"`
+	Raw        string            `json:"raw"`
+	URLEncoded []postmanKeyValue `json:"urlencoded"`
+	FormData   []postmanKeyValue `json:"formdata"`
+	GraphQL    *struct {
+		Query     string `json:"query"`
+		Variables string `json:"variables"`
+	} `json:"graphql"`
+	Options struct {
+		Raw struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+	Disabled bool `json:"disabled"`
+}
+
+type postmanAuth struct {
+	Type   string            `json:"type"`
+	Bearer []postmanKeyValue `json:"bearer"`
+	Basic  []postmanKeyValue `json:"basic"`
+	APIKey []postmanKeyValue `json:"apikey"`
+}
+
+type postmanEnvironment struct {
+	Name   string            `json:"name"`
+	Values []postmanKeyValue `json:"values"`
+}
+
+// PostmanImport is the result of converting a Postman collection.
+type PostmanImport struct {
+	Collections []config.TestCollection
+	Warnings    []string
+}
+
+// ParsePostman converts a Postman v2.1 collection into test collections: the
+// top-level requests form a collection named after the Postman collection,
+// and every folder becomes its own collection. Variables are resolved from
+// the optional environment file contents, then from collection variables.
+func ParsePostman(collectionData, environmentData []byte) (*PostmanImport, error) {
+	var coll postmanCollection
+	if err := json.Unmarshal(collectionData, &coll); err != nil {
+		return nil, fmt.Errorf("parsing Postman collection: %w", err)
+	}
+	if coll.Info.Schema != "" && !strings.Contains(coll.Info.Schema, "v2.1") && !strings.Contains(coll.Info.Schema, "v2.0") {
+		return nil, fmt.Errorf("unsupported Postman collection schema %q; export as v2.1", coll.Info.Schema)
+	}
+
+	vars := make(map[string]string)
+	for _, v := range coll.Variable {
+		if v.active() {
+			vars[v.Key] = v.value()
+		}
+	}
+	if len(environmentData) > 0 {
+		var env postmanEnvironment
+		if err := json.Unmarshal(environmentData, &env); err != nil {
+			return nil, fmt.Errorf("parsing Postman environment: %w", err)
+		}
+		for _, v := range env.Values {
+			if v.active() {
+				vars[v.Key] = v.value()
+			}
+		}
+	}
+
+	p := &postmanImporter{result: &PostmanImport{}}
+	rootName := coll.Info.Name
+	if rootName == "" {
+		rootName = "postman"
+	}
+	p.walk(rootName, coll.Item, vars, coll.Auth)
+	return p.result, nil
+}
+
+type postmanImporter struct {
+	result *PostmanImport
+}
+
+func (p *postmanImporter) warnf(format string, args ...any) {
+	p.result.Warnings = append(p.result.Warnings, fmt.Sprintf(format, args...))
+}
+
+func (p *postmanImporter) walk(collectionName string, items []postmanItem, vars map[string]string, auth *postmanAuth) {
+	collection := config.TestCollection{Name: config.SanitizeFilename(collectionName)}
+	usedNames := make(map[string]int)
+	position := len(p.result.Collections) // Parents precede their folders
+
+	for _, item := range items {
+		itemVars := vars
+		if len(item.Variable) > 0 {
+			itemVars = make(map[string]string, len(vars)+len(item.Variable))
+			for k, v := range vars {
+				itemVars[k] = v
+			}
+			for _, v := range item.Variable {
+				if v.active() {
+					itemVars[v.Key] = v.value()
+				}
+			}
+		}
+		itemAuth := auth
+		if item.Auth != nil {
+			itemAuth = item.Auth
+		}
+
+		if item.Request == nil {
+			// A folder: its requests form a collection of their own.
+			p.walk(collectionName+"_"+item.Name, item.Item, itemVars, itemAuth)
+			continue
+		}
+
+		cfg, err := p.convertRequest(item.Name, item.Request, itemVars, itemAuth)
+		if err != nil {
+			p.warnf("%s / %s: skipped: %v", collectionName, item.Name, err)
+			continue
+		}
+		name := config.SanitizeFilename(item.Name)
+		usedNames[name]++
+		if n := usedNames[name]; n > 1 {
+			name = fmt.Sprintf("%s_%d", name, n)
+		}
+		collection.Tests = append(collection.Tests, config.Test{Name: name, Config: cfg})
+	}
+
+	if len(collection.Tests) > 0 {
+		p.result.Collections = append(p.result.Collections, config.TestCollection{})
+		copy(p.result.Collections[position+1:], p.result.Collections[position:])
+		p.result.Collections[position] = collection
+	}
+}
+
+func (p *postmanImporter) convertRequest(itemName string, r *postmanRequest, vars map[string]string, auth *postmanAuth) (config.BenchmarkConfig, error) {
+	cfg := config.BenchmarkConfig{
+		Method:      strings.ToUpper(r.Method),
+		Threads:     DefaultThreads,
+		Connections: DefaultConnections,
+		Duration:    DefaultDuration,
+	}
+	if cfg.Method == "" {
+		cfg.Method = "GET"
+	}
+	unresolved := map[string]bool{}
+	expand := func(s string) string {
+		return postmanVarPattern.ReplaceAllStringFunc(s, func(m string) string {
+			name := strings.TrimSpace(m[2 : len(m)-2])
+			if v, ok := vars[name]; ok {
+				return v
+			}
+			unresolved[name] = true
+			return m
+		})
+	}
+
+	rawURL := expand(r.URL.Raw)
+	if rawURL == "" {
+		return cfg, fmt.Errorf("request has no URL")
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	cfg.TargetURL = rawURL
+
+	headers := make(map[string]string)
+	for _, h := range r.Header {
+		if h.active() {
+			headers[expand(h.Key)] = expand(h.value())
+		}
+	}
+
+	if r.Auth != nil {
+		auth = r.Auth
+	}
+	if auth != nil {
+		if err := p.applyAuth(&cfg, headers, auth, expand); err != nil {
+			p.warnf("%s: %v", itemName, err)
+		}
+	}
+
+	if r.Body != nil && !r.Body.Disabled {
+		switch r.Body.Mode {
+		case "raw":
+			cfg.Payload = expand(r.Body.Raw)
+			if _, ok := findHeader(headers, "Content-Type"); !ok && cfg.Payload != "" {
+				switch r.Body.Options.Raw.Language {
+				case "json":
+					headers["Content-Type"] = "application/json"
+				case "xml":
+					headers["Content-Type"] = "application/xml"
+				case "html":
+					headers["Content-Type"] = "text/html"
+				case "text":
+					headers["Content-Type"] = "text/plain"
+				}
+			}
+		case "urlencoded":
+			form := url.Values{}
+			for _, kv := range r.Body.URLEncoded {
+				if kv.active() {
+					form.Add(expand(kv.Key), expand(kv.value()))
+				}
+			}
+			cfg.Payload = form.Encode()
+			if _, ok := findHeader(headers, "Content-Type"); !ok {
+				headers["Content-Type"] = "application/x-www-form-urlencoded"
+			}
+		case "graphql":
+			if r.Body.GraphQL != nil {
+				body := map[string]any{"query": expand(r.Body.GraphQL.Query)}
+				if vars := strings.TrimSpace(expand(r.Body.GraphQL.Variables)); vars != "" {
+					body["variables"] = json.RawMessage(vars)
+				}
+				data, err := json.Marshal(body)
+				if err != nil {
+					return cfg, fmt.Errorf("encoding GraphQL body: %w", err)
+				}
+				cfg.Payload = string(data)
+				if _, ok := findHeader(headers, "Content-Type"); !ok {
+					headers["Content-Type"] = "application/json"
+				}
+			}
+		case "formdata":
+			for _, kv := range r.Body.FormData {
+				if !kv.active() {
+					continue
+				}
+				field := config.FormField{Name: expand(kv.Key), ContentType: kv.ContentType}
+				if kv.Type == "file" {
+					src := kv.Src
+					if list, ok := src.([]any); ok && len(list) > 0 {
+						src = list[0]
+					}
+					if src == nil {
+						p.warnf("%s: form field %q has no file; it was dropped", itemName, field.Name)
+						continue
+					}
+					field.File = expand(scalarString(src))
+				} else {
+					field.Value = expand(kv.value())
+				}
+				cfg.Form = append(cfg.Form, field)
+			}
+			if len(cfg.Form) > 0 {
+				cfg.BodyType = config.BodyMultipart
+				for name := range headers {
+					if strings.EqualFold(name, "Content-Type") {
+						delete(headers, name)
+					}
+				}
+			}
+		case "file":
+			p.warnf("%s: %s bodies are not supported; the body was dropped", itemName, r.Body.Mode)
+		}
+	}
+
+	if len(headers) > 0 {
+		cfg.Headers = headers
+	}
+	if len(unresolved) > 0 {
+		names := make([]string, 0, len(unresolved))
+		for name := range unresolved {
+			names = append(names, name)
+		}
+		p.warnf("%s: unresolved variables %s", itemName, strings.Join(names, ", "))
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+func (p *postmanImporter) applyAuth(cfg *config.BenchmarkConfig, headers map[string]string, auth *postmanAuth, expand func(string) string) error {
+	param := func(list []postmanKeyValue, key string) string {
+		for _, kv := range list {
+			if kv.Key == key {
+				return expand(kv.value())
+			}
+		}
+		return ""
+	}
+	switch auth.Type {
+	case "", "noauth":
+	case "bearer":
+		headers["Authorization"] = "Bearer " + param(auth.Bearer, "token")
+	case "basic":
+		creds := param(auth.Basic, "username") + ":" + param(auth.Basic, "password")
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(creds))
+	case "apikey":
+		key, value := param(auth.APIKey, "key"), param(auth.APIKey, "value")
+		if param(auth.APIKey, "in") == "query" {
+			sep := "?"
+			if strings.Contains(cfg.TargetURL, "?") {
+				sep = "&"
+			}
+			cfg.TargetURL += sep + url.QueryEscape(key) + "=" + url.QueryEscape(value)
+		} else {
+			headers[key] = value
+		}
+	default:
+		return fmt.Errorf("auth type %q is not supported", auth.Type)
+	}
+	return nil
+}
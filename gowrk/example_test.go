@@ -0,0 +1,31 @@
+package gowrk_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/Th4phat/go-wrk/gowrk"
+)
+
+func ExampleRun() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	result, err := gowrk.Run(context.Background(), gowrk.Config{
+		TargetURL:   server.URL,
+		Threads:     2,
+		Connections: 4,
+		Duration:    "1s",
+		Thresholds:  &gowrk.Thresholds{LatencyP99: "1s"},
+	})
+	if err != nil {
+		fmt.Println("run failed:", err)
+		return
+	}
+	fmt.Println("passed:", result.Passed())
+	// Output: passed: true
+}
@@ -0,0 +1,171 @@
+// Package gowrk runs go-wrk benchmarks from Go code, such as tests and
+// benchmarks:
+//
+//	func TestCheckoutLoad(t *testing.T) {
+//		gowrk.Require(t, gowrk.Config{
+//			TargetURL:   server.URL + "/checkout",
+//			Threads:     8,
+//			Connections: 16,
+//			Duration:    "5s",
+//			Thresholds:  &gowrk.Thresholds{LatencyP99: "50ms"},
+//		})
+//	}
+//
+// Run is the general form. It blocks until the benchmark finishes or its
+// context is done, and reports progress to an Observer.
+package gowrk
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/Th4phat/go-wrk/benchmark"
+	"github.com/Th4phat/go-wrk/config"
+	"github.com/Th4phat/go-wrk/metrics"
+)
+
+type (
+	// Config describes a benchmark, as a test file does.
+	Config = config.BenchmarkConfig
+	// Thresholds are pass/fail limits on a result.
+	Thresholds = config.Thresholds
+	// Result holds the statistics of a finished benchmark.
+	Result = metrics.BenchmarkResult
+	// Progress is a point-in-time update while a benchmark runs.
+	Progress = metrics.ProgressUpdate
+
+	// Driver sends the operations of a benchmark over one protocol.
+	Driver = benchmark.Driver
+	// DriverFactory creates a driver for one run.
+	DriverFactory = benchmark.DriverFactory
+	// ClientOptions are the client settings passed to a DriverFactory.
+	ClientOptions = benchmark.ClientOptions
+)
+
+// RegisterDriver makes a driver available to configs whose driver field
+// names it. Call it from an init function; it panics if the name is taken.
+func RegisterDriver(name string, factory DriverFactory) {
+	benchmark.RegisterDriver(name, factory)
+}
+
+// Observer receives progress while a benchmark runs. Progress is called from
+// the goroutine running Run; updates that arrive while it is busy are
+// skipped rather than queued, so a slow observer never slows the benchmark.
+type Observer interface {
+	Progress(Progress)
+}
+
+// ObserverFunc adapts a function to an Observer.
+type ObserverFunc func(Progress)
+
+func (f ObserverFunc) Progress(p Progress) { f(p) }
+
+// Option changes how Run runs a benchmark.
+type Option func(*options)
+
+type options struct {
+	observers  []Observer
+	client     benchmark.ClientOptions
+	thresholds *Thresholds
+}
+
+// WithObserver reports progress to o. It may be given more than once.
+func WithObserver(o Observer) Option {
+	return func(opts *options) { opts.observers = append(opts.observers, o) }
+}
+
+// WithTimeouts sets how long the client waits to read a response and to
+// write a request. Zero keeps the default of 30s and 10s.
+func WithTimeouts(read, write time.Duration) Option {
+	return func(opts *options) { opts.client.ReadTimeout, opts.client.WriteTimeout = read, write }
+}
+
+// WithConnWaitTimeout sets how long a request waits for a free connection
+// when all are busy. Zero keeps the default of 30s.
+func WithConnWaitTimeout(d time.Duration) Option {
+	return func(opts *options) { opts.client.MaxConnWaitTimeout = d }
+}
+
+// WithTLSConfig sets the TLS configuration of the client, for example to
+// trust a test server's certificate.
+func WithTLSConfig(c *tls.Config) Option {
+	return func(opts *options) { opts.client.TLSConfig = c }
+}
+
+// WithDialer opens the client's connections with dial, for example to reach
+// an in-memory listener.
+func WithDialer(dial func(addr string) (net.Conn, error)) Option {
+	return func(opts *options) { opts.client.Dial = dial }
+}
+
+// WithThresholds checks the result against t instead of the thresholds of
+// the config. It only affects Require.
+func WithThresholds(t Thresholds) Option {
+	return func(opts *options) { opts.thresholds = &t }
+}
+
+// Run runs a benchmark and waits for its result. Cancelling ctx stops the
+// benchmark; Run then returns the partial result with ctx's error.
+//
+// The error is also set if the config is invalid or the run fails. Failed
+// requests are not an error: they are counted in the result, and thresholds
+// judge them.
+func Run(ctx context.Context, cfg Config, opts ...Option) (Result, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := cfg.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	engine := benchmark.NewEngine()
+	engine.SetClientOptions(o.client)
+	progressChan := make(chan metrics.ProgressUpdate)
+	resultChan := make(chan metrics.BenchmarkResult)
+	if err := engine.Start(cfg, progressChan, resultChan); err != nil {
+		return Result{}, fmt.Errorf("starting benchmark: %w", err)
+	}
+
+	var result Result
+	gotResult := false
+	done := ctx.Done()
+	for progressChan != nil || resultChan != nil {
+		select {
+		case p, ok := <-progressChan:
+			if !ok {
+				progressChan = nil
+				continue
+			}
+			for _, obs := range o.observers {
+				obs.Progress(p)
+			}
+		case r, ok := <-resultChan:
+			if !ok {
+				resultChan = nil
+				continue
+			}
+			result, gotResult = r, true
+		case <-done:
+			engine.Stop()
+			done = nil
+		}
+	}
+	engine.Wait()
+
+	switch {
+	case ctx.Err() != nil:
+		return result, ctx.Err()
+	case !gotResult:
+		return result, errors.New("benchmark finished without a result")
+	}
+	var requestErrors *metrics.RequestErrors
+	if result.Error != nil && !errors.As(result.Error, &requestErrors) {
+		return result, result.Error
+	}
+	return result, nil
+}
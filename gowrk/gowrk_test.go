@@ -0,0 +1,103 @@
+package gowrk_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Th4phat/go-wrk/gowrk"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRunReturnsContextErrorWhenCancelled(t *testing.T) {
+	server := newServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(300*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := gowrk.Run(ctx, gowrk.Config{
+		TargetURL:   server.URL,
+		Threads:     1,
+		Connections: 2,
+		Duration:    "30s",
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run error = %v, want %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Run returned %s after cancellation", elapsed)
+	}
+}
+
+func TestRunDeliversProgressToObservers(t *testing.T) {
+	server := newServer(t)
+	var updates atomic.Int64
+	result, err := gowrk.Run(context.Background(), gowrk.Config{
+		TargetURL:   server.URL,
+		Threads:     1,
+		Connections: 2,
+		Duration:    "2s",
+	}, gowrk.WithObserver(gowrk.ObserverFunc(func(gowrk.Progress) {
+		updates.Add(1)
+	})))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if updates.Load() == 0 {
+		t.Error("observer received no progress")
+	}
+	if result.TotalRequestsCompleted == 0 {
+		t.Error("no requests completed")
+	}
+}
+
+// recorder is a testing.TB that records failures instead of failing the
+// test running it.
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	r.fatal = true
+}
+
+func TestRequireFailsOnBrokenThreshold(t *testing.T) {
+	server := newServer(t)
+	rec := &recorder{TB: t}
+	gowrk.Require(rec, gowrk.Config{
+		TargetURL:   server.URL,
+		Threads:     1,
+		Connections: 2,
+		Duration:    "1s",
+		Thresholds:  &gowrk.Thresholds{LatencyP99: "1ns"},
+	})
+	if rec.fatal {
+		t.Fatalf("run failed: %v", rec.errors)
+	}
+	if len(rec.errors) != 1 {
+		t.Fatalf("Require reported %d errors, want 1: %v", len(rec.errors), rec.errors)
+	}
+	t.Logf("reported: %s", rec.errors[0])
+}
@@ -0,0 +1,58 @@
+package gowrk
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Require runs a benchmark for a test or benchmark and fails tb if the run
+// fails or the result breaks a threshold: those of the config, or those
+// given with WithThresholds. Without an error rate threshold, any failed
+// request fails tb.
+//
+// In a benchmark, call Require once rather than b.N times: a run longer than
+// -benchtime makes the benchmark function run only once. The throughput,
+// latency and error rate are reported as benchmark metrics.
+func Require(tb testing.TB, cfg Config, opts ...Option) Result {
+	tb.Helper()
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	thresholds := o.thresholds
+	if thresholds == nil {
+		thresholds = cfg.Thresholds
+	}
+
+	ctx := context.Background()
+	if t, ok := tb.(interface{ Deadline() (time.Time, bool) }); ok {
+		if deadline, ok := t.Deadline(); ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithDeadline(ctx, deadline)
+			defer cancel()
+		}
+	}
+
+	result, err := Run(ctx, cfg, opts...)
+	if err != nil {
+		tb.Fatalf("go-wrk run of %s: %v", cfg.TargetURL, err)
+	}
+	if b, ok := tb.(*testing.B); ok {
+		b.ReportMetric(result.Throughput, "req/s")
+		b.ReportMetric(float64(result.LatencyP50)/float64(time.Millisecond), "p50-ms")
+		b.ReportMetric(float64(result.LatencyP99)/float64(time.Millisecond), "p99-ms")
+		b.ReportMetric(result.ErrorRate, "%errors")
+	}
+	if result.Error != nil && (thresholds == nil || thresholds.ErrorRate == nil) {
+		tb.Errorf("go-wrk: %v", result.Error)
+	}
+	if thresholds != nil {
+		for _, check := range result.CheckThresholds(*thresholds) {
+			if !check.Passed {
+				tb.Errorf("go-wrk threshold: %s", check)
+			}
+		}
+	}
+	return result
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"slices"
 
+	"github.com/Th4phat/go-wrk/cli"
 	"github.com/Th4phat/go-wrk/config"
 	"github.com/Th4phat/go-wrk/tui"
 
@@ -11,6 +14,18 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		os.Exit(cli.Run(os.Args[1:]))
+	}
+
+	env := flag.String("env", "", "environment to select at startup, as defined in collection files")
+	flag.Usage = func() {
+		cli.Run([]string{"help"})
+		fmt.Fprintln(flag.CommandLine.Output(), "\nTUI flags:")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
 	var logFile *os.File
 	var err error
 
@@ -32,7 +47,7 @@ func main() {
 		os.Exit(1) // Or return an error
 	}
 
-	testCollections, err := config.LoadTestCollections(configDir)
+	testCollections, loadReport, err := config.LoadTestCollections(configDir)
 	if err != nil {
 		if logFile != nil {
 			fmt.Fprintf(logFile, "Error loading test collections: %v\n", err)
@@ -41,7 +56,18 @@ func main() {
 		os.Exit(1)
 	}
 
-	m := tui.NewModel(testCollections, logFile)
+	if *env != "" {
+		known := false
+		for _, collection := range testCollections {
+			known = known || slices.Contains(collection.EnvironmentNames(), *env)
+		}
+		if !known {
+			fmt.Fprintf(os.Stderr, "Unknown environment %q: no collection defines it.\n", *env)
+			os.Exit(2)
+		}
+	}
+
+	m := tui.NewModel(testCollections, loadReport, *env, logFile)
 
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
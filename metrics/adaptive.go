@@ -0,0 +1,13 @@
+package metrics
+
+import "time"
+
+// ConcurrencyDecision is one step of the adaptive concurrency controller.
+type ConcurrencyDecision struct {
+	Timestamp  time.Time     `json:"timestamp"`
+	From       int           `json:"from"`        // Active workers before the decision
+	To         int           `json:"to"`          // Active workers after it
+	LatencyP95 time.Duration `json:"latency_p95"` // Measured over the interval the decision is based on
+	ErrorRate  float64       `json:"error_rate"`  // Percent, over the same interval
+	Reason     string        `json:"reason"`      // Why the controller moved, such as "p95 312ms above 200ms"
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// RunFileVersion is the version of the run file format written by this
+// build.
+const RunFileVersion = 1
+
+// TestResult is the result of one test of a run.
+type TestResult struct {
+	Collection string          `json:"collection,omitempty"`
+	Test       string          `json:"test,omitempty"`
+	Result     BenchmarkResult `json:"result"`
+}
+
+// Name returns "collection/test", or whichever of the two is known.
+func (t TestResult) Name() string {
+	switch {
+	case t.Collection != "" && t.Test != "":
+		return t.Collection + "/" + t.Test
+	case t.Test != "":
+		return t.Test
+	case t.Collection != "":
+		return t.Collection
+	}
+	return "unnamed test"
+}
+
+// RunFile is the saved outcome of a run of one or more tests. Durations are
+// in nanoseconds.
+type RunFile struct {
+	Version     int          `json:"version"`
+	Environment string       `json:"environment,omitempty"`
+	Tests       []TestResult `json:"tests"`
+}
+
+// MarshalJSON encodes the result with its error as a message.
+func (r BenchmarkResult) MarshalJSON() ([]byte, error) {
+	type plain BenchmarkResult
+	out := struct {
+		plain
+		Error string `json:"error,omitempty"`
+	}{plain: plain(r)}
+	if r.Error != nil {
+		out.Error = r.Error.Error()
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes a result written by MarshalJSON.
+func (r *BenchmarkResult) UnmarshalJSON(data []byte) error {
+	type plain BenchmarkResult
+	var in struct {
+		plain
+		Error string `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*r = BenchmarkResult(in.plain)
+	if requestErrors := (&RequestErrors{Count: r.TotalErrors}); in.Error == requestErrors.Error() {
+		r.Error = requestErrors
+	} else if in.Error != "" {
+		r.Error = errors.New(in.Error)
+	}
+	return nil
+}
+
+// WriteRunFile saves a run as indented JSON.
+func WriteRunFile(path string, f RunFile) error {
+	f.Version = RunFileVersion
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding results: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing results: %w", err)
+	}
+	return nil
+}
+
+// ReadRunFile loads a run saved by WriteRunFile.
+func ReadRunFile(path string) (RunFile, error) {
+	var f RunFile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return f, fmt.Errorf("reading results: %w", err)
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return f, fmt.Errorf("parsing results %s: %w", path, err)
+	}
+	if f.Version > RunFileVersion {
+		return f, fmt.Errorf("results %s have version %d; this go-wrk reads up to version %d", path, f.Version, RunFileVersion)
+	}
+	return f, nil
+}
@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Latency buckets of the per-second histograms are log-scaled: bucket i
+// covers [MinBucketLatency*10^(i/5), MinBucketLatency*10^((i+1)/5)). The
+// first bucket also holds anything faster, the last anything slower.
+const (
+	LatencyBucketsPerDecade = 5
+	LatencyBucketCount      = 36
+	MinBucketLatency        = 50 * time.Microsecond
+)
+
+// LatencyBucket returns the histogram bucket of a latency.
+func LatencyBucket(d time.Duration) int {
+	if d <= MinBucketLatency {
+		return 0
+	}
+	i := int(math.Log10(float64(d)/float64(MinBucketLatency)) * LatencyBucketsPerDecade)
+	return min(i, LatencyBucketCount-1)
+}
+
+// LatencyBucketBounds returns the lower and upper bound of a bucket.
+func LatencyBucketBounds(i int) (time.Duration, time.Duration) {
+	bound := func(i int) time.Duration {
+		return time.Duration(float64(MinBucketLatency) * math.Pow(10, float64(i)/LatencyBucketsPerDecade))
+	}
+	return bound(i), bound(i + 1)
+}
+
+// NewLatencyHistogram counts sorted latencies per bucket, leaving out the
+// empty buckets at the end.
+func NewLatencyHistogram(sorted []time.Duration) []int {
+	if len(sorted) == 0 {
+		return nil
+	}
+	counts := make([]int, LatencyBucket(sorted[len(sorted)-1])+1)
+	for _, d := range sorted {
+		counts[LatencyBucket(d)]++
+	}
+	return counts
+}
+
+// MeasuredHistogram sums the latency histograms of the measured points of a
+// series, leaving out the warm-up and cool-down.
+func MeasuredHistogram(series []SeriesPoint) []int {
+	var counts []int
+	for _, p := range series {
+		if p.Phase != PhaseMeasure && p.Phase != "" {
+			continue
+		}
+		if len(p.Histogram) > len(counts) {
+			counts = append(counts, make([]int, len(p.Histogram)-len(counts))...)
+		}
+		for i, n := range p.Histogram {
+			counts[i] += n
+		}
+	}
+	return counts
+}
+
+// HistogramPercentile estimates the latency at percentile p of a histogram,
+// interpolating on the log scale within the bucket it falls in. It returns 0
+// for an empty histogram.
+func HistogramPercentile(counts []int, p float64) time.Duration {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(total)
+	seen := 0
+	for i, n := range counts {
+		if n == 0 {
+			continue
+		}
+		if float64(seen+n) >= rank {
+			lower, upper := LatencyBucketBounds(i)
+			frac := (rank - float64(seen)) / float64(n)
+			return time.Duration(float64(lower) * math.Pow(float64(upper)/float64(lower), frac))
+		}
+		seen += n
+	}
+	_, upper := LatencyBucketBounds(len(counts) - 1)
+	return upper
+}
+
+// HistogramRange returns the lowest and highest non-empty bucket over every
+// point of a series; ok is false if all histograms are empty.
+func HistogramRange(series []SeriesPoint) (lo, hi int, ok bool) {
+	lo = LatencyBucketCount
+	for _, p := range series {
+		for i, n := range p.Histogram {
+			if n > 0 {
+				lo, hi = min(lo, i), max(hi, i)
+				ok = true
+			}
+		}
+	}
+	return lo, hi, ok
+}
+
+// FormatLatency renders a latency compactly for axis labels: 850µs, 1.2ms,
+// 35ms or 1.5s.
+func FormatLatency(d time.Duration) string {
+	switch {
+	case d < time.Millisecond:
+		return fmt.Sprintf("%dµs", d.Microseconds())
+	case d < 10*time.Millisecond:
+		return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	default:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+}
+
+// NiceCeil rounds v up to 1, 2 or 5 times a power of ten, so a chart axis
+// maximum is a readable number.
+func NiceCeil(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	exp := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, f := range []float64{1, 2, 5, 10} {
+		if v <= f*exp {
+			return f * exp
+		}
+	}
+	return 10 * exp
+}
@@ -15,30 +15,46 @@ type ProgressUpdate struct {
 	RequestsAttempted int
 	RequestsCompleted int
 	Errors            int
-	CurrentThroughput float64         // Instantaneous or short-window throughput
-	CurrentErrorRate  float64         // Instantaneous or short-window error rate
-	LatencyAvg        time.Duration   // Cumulative Avg
-	LatencyP95        time.Duration   // Cumulative P95
-	LatencyP99        time.Duration   // Cumulative P99
-	LatencyData       []time.Duration // Recent raw data for live histogram
+	CurrentThroughput float64               // Instantaneous or short-window throughput
+	CurrentErrorRate  float64               // Instantaneous or short-window error rate
+	LatencyAvg        time.Duration         // Cumulative Avg
+	LatencyP95        time.Duration         // Cumulative P95
+	LatencyP99        time.Duration         // Cumulative P99
+	LatencyData       []time.Duration       // Recent raw data for live histogram
+	ActiveUsers       int                   // Virtual users started and not yet stopped; 0 outside virtual user mode
+	DroppedArrivals   int                   // Open-model arrivals skipped because max_in_flight requests were outstanding
+	LateArrivals      int                   // Open-model arrivals sent behind their schedule
+	Search            *SearchResult         // Steps so far of a capacity search; nil outside search mode
+	Concurrency       int                   // Active workers under adaptive concurrency; 0 outside that mode
+	Decisions         []ConcurrencyDecision // Controller decisions since the previous update
+	Phase             string                // One of the Phase* constants; the counts above cover this phase
+	Series            []SeriesPoint         // Per-second series so far; shared, do not modify
 }
 
 // BenchmarkResult holds the final aggregated results of a benchmark run.
 type BenchmarkResult struct {
-	Config                 *config.BenchmarkConfig // Include config used
-	TotalRequestsSent      int
-	TotalRequestsCompleted int
-	TotalErrors            int
-	TotalDuration          time.Duration
-	Throughput             float64
-	ErrorRate              float64
-	LatencyAvg             time.Duration
-	LatencyP50             time.Duration // Median
-	LatencyP95             time.Duration
-	LatencyP99             time.Duration
-	LatencyData            []time.Duration // Final complete latency data
-	ErrorDetails           map[string]int  // Count of specific errors encountered
-	Error                  error           // *** ADDED: Field for critical run error ***
+	Config                 *config.BenchmarkConfig `json:"config"` // Include config used
+	TotalRequestsSent      int                     `json:"total_requests_sent"`
+	TotalRequestsCompleted int                     `json:"total_requests_completed"`
+	TotalErrors            int                     `json:"total_errors"`
+	TotalDuration          time.Duration           `json:"total_duration"`
+	Throughput             float64                 `json:"throughput"`
+	ErrorRate              float64                 `json:"error_rate"`
+	LatencyAvg             time.Duration           `json:"latency_avg"`
+	LatencyP50             time.Duration           `json:"latency_p50"` // Median
+	LatencyP95             time.Duration           `json:"latency_p95"`
+	LatencyP99             time.Duration           `json:"latency_p99"`
+	LatencyData            []time.Duration         `json:"-"`                          // Final complete latency data; too large to save, the series histograms stand in
+	ErrorDetails           map[string]int          `json:"error_details,omitempty"`    // Count of specific errors encountered
+	Error                  error                   `json:"-"`                          // *** ADDED: Field for critical run error ***
+	DroppedArrivals        int                     `json:"dropped_arrivals,omitempty"` // Open-model arrivals skipped because max_in_flight requests were outstanding
+	LateArrivals           int                     `json:"late_arrivals,omitempty"`    // Open-model arrivals sent behind their schedule
+	Search                 *SearchResult           `json:"search,omitempty"`           // Outcome of a capacity search; nil outside search mode
+	Concurrency            int                     `json:"concurrency,omitempty"`      // Active workers at the end under adaptive concurrency
+	Decisions              []ConcurrencyDecision   `json:"decisions,omitempty"`        // Every decision of the adaptive concurrency controller
+	Warmup                 *PhaseStats             `json:"warmup,omitempty"`           // Samples of the warm-up window; nil without one
+	Cooldown               *PhaseStats             `json:"cooldown,omitempty"`         // Samples of the cool-down window; nil without one
+	Series                 []SeriesPoint           `json:"series,omitempty"`           // Per-second series of the whole run, warm-up and cool-down included
 }
 
 // HttpStatusError represents a non-2xx HTTP response.
@@ -51,6 +67,16 @@ func (e *HttpStatusError) Error() string {
 	return fmt.Sprintf("HTTP status error: %d %s", e.StatusCode, e.Status)
 }
 
+// RequestErrors is the error of a run that reached its end but had failed
+// requests.
+type RequestErrors struct {
+	Count int
+}
+
+func (e *RequestErrors) Error() string {
+	return fmt.Sprintf("benchmark completed with %d errors", e.Count)
+}
+
 // --- Calculation Helpers ---
 
 func CalculateAverage(data []time.Duration) time.Duration {
@@ -0,0 +1,45 @@
+package metrics
+
+import "time"
+
+// Phases of a run. Only samples from the measured phase count towards the
+// main statistics.
+const (
+	PhaseWarmup   = "warm-up"
+	PhaseMeasure  = "measuring"
+	PhaseCooldown = "cool-down"
+)
+
+// PhaseStats summarizes the samples of a warm-up or cool-down window.
+type PhaseStats struct {
+	Duration          time.Duration `json:"duration"`
+	RequestsCompleted int           `json:"requests_completed"`
+	Errors            int           `json:"errors"`
+	Throughput        float64       `json:"throughput"`
+	ErrorRate         float64       `json:"error_rate"`
+	LatencyAvg        time.Duration `json:"latency_avg"`
+	LatencyP50        time.Duration `json:"latency_p50"`
+	LatencyP95        time.Duration `json:"latency_p95"`
+	LatencyP99        time.Duration `json:"latency_p99"`
+}
+
+// NewPhaseStats summarizes a window of duration d. It sorts latencies.
+func NewPhaseStats(completed, errors int, latencies []time.Duration, d time.Duration) *PhaseStats {
+	SortLatencies(latencies)
+	s := &PhaseStats{
+		Duration:          d,
+		RequestsCompleted: completed,
+		Errors:            errors,
+		LatencyAvg:        CalculateAverage(latencies),
+		LatencyP50:        CalculatePercentile(latencies, 50),
+		LatencyP95:        CalculatePercentile(latencies, 95),
+		LatencyP99:        CalculatePercentile(latencies, 99),
+	}
+	if d > 0 {
+		s.Throughput = float64(completed) / d.Seconds()
+	}
+	if completed+errors > 0 {
+		s.ErrorRate = float64(errors) / float64(completed+errors) * 100
+	}
+	return s
+}
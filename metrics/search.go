@@ -0,0 +1,36 @@
+package metrics
+
+import "time"
+
+// SearchStep is the outcome of one fixed-rate step of a capacity search.
+type SearchStep struct {
+	Rate       float64       `json:"rate"`       // Target arrivals per second
+	Throughput float64       `json:"throughput"` // Achieved successful requests per second
+	LatencyP50 time.Duration `json:"latency_p50"`
+	LatencyP99 time.Duration `json:"latency_p99"`
+	ErrorRate  float64       `json:"error_rate"`
+	Dropped    int           `json:"dropped,omitempty"`    // Arrivals dropped at the in-flight cap
+	Passed     bool          `json:"passed"`               // The step met the SLO at its rate
+	Violations []string      `json:"violations,omitempty"` // Why the step failed
+}
+
+// SearchResult is the progress or outcome of a capacity search.
+type SearchResult struct {
+	Steps []SearchStep `json:"steps"`
+	// SustainableRate is the highest rate that passed, and
+	// SustainableThroughput the throughput achieved at it; both are 0 if no
+	// step passed.
+	SustainableRate       float64 `json:"sustainable_rate"`
+	SustainableThroughput float64 `json:"sustainable_throughput"`
+	CurrentRate           float64 `json:"current_rate,omitempty"` // Rate of the running step; 0 once the search ends
+}
+
+// Clone returns a copy of the search that shares nothing with s.
+func (s *SearchResult) Clone() *SearchResult {
+	if s == nil {
+		return nil
+	}
+	out := *s
+	out.Steps = append([]SearchStep(nil), s.Steps...)
+	return &out
+}
@@ -0,0 +1,29 @@
+package metrics
+
+import "time"
+
+// SeriesPoint holds the samples of one second of a run.
+type SeriesPoint struct {
+	Offset          time.Duration  `json:"offset"`   // Start of the interval, from the start of the run
+	Duration        time.Duration  `json:"duration"` // Length of the interval; the last one may be shorter
+	Phase           string         `json:"phase"`
+	Completed       int            `json:"completed"`
+	Errors          int            `json:"errors"`
+	ErrorsByType    map[string]int `json:"errors_by_type,omitempty"`
+	Throughput      float64        `json:"throughput"` // Completed requests per second
+	LatencyP50      time.Duration  `json:"latency_p50"`
+	LatencyP90      time.Duration  `json:"latency_p90"`
+	LatencyP99      time.Duration  `json:"latency_p99"`
+	BytesIn         int64          `json:"bytes_in"`  // Read from the sockets, including TLS
+	BytesOut        int64          `json:"bytes_out"` // Written to the sockets, including TLS
+	OpenConnections int            `json:"open_connections"`
+	Histogram       []int          `json:"histogram,omitempty"` // Latencies per LatencyBucket; trailing empty buckets are left out
+}
+
+// ErrorRate returns the percentage of the interval's requests that failed.
+func (p SeriesPoint) ErrorRate() float64 {
+	if p.Completed+p.Errors == 0 {
+		return 0
+	}
+	return float64(p.Errors) / float64(p.Completed+p.Errors) * 100
+}
@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	config "github.com/Th4phat/go-wrk/config"
+)
+
+// Latency returns the result's average latency for percentile 0, or the
+// latency at percentile p. Results read from a file have no raw latencies;
+// other percentiles are then estimated from the series histograms.
+func (r BenchmarkResult) Latency(p float64) time.Duration {
+	switch p {
+	case 0:
+		return r.LatencyAvg
+	case 50:
+		return r.LatencyP50
+	case 95:
+		return r.LatencyP95
+	case 99:
+		return r.LatencyP99
+	}
+	if len(r.LatencyData) == 0 {
+		return HistogramPercentile(MeasuredHistogram(r.Series), p)
+	}
+	return CalculatePercentile(r.LatencyData, p)
+}
+
+// ThresholdCheck is the outcome of one threshold against a result.
+type ThresholdCheck struct {
+	Metric string // Such as "p99 latency" or "error rate"
+	Limit  string
+	Actual string
+	Passed bool
+}
+
+func (c ThresholdCheck) String() string {
+	return fmt.Sprintf("%s %s exceeds %s", c.Metric, c.Actual, c.Limit)
+}
+
+// CheckThresholds checks the result against every threshold that is set.
+func (r BenchmarkResult) CheckThresholds(t config.Thresholds) []ThresholdCheck {
+	var checks []ThresholdCheck
+	for _, limit := range t.LatencyLimits() {
+		got := r.Latency(limit.Percentile)
+		checks = append(checks, ThresholdCheck{
+			Metric: limit.Name + " latency",
+			Limit:  limit.Max.String(),
+			Actual: got.Round(time.Microsecond).String(),
+			Passed: got <= limit.Max,
+		})
+	}
+	if t.ErrorRate != nil {
+		checks = append(checks, ThresholdCheck{
+			Metric: "error rate",
+			Limit:  fmt.Sprintf("%g%%", *t.ErrorRate),
+			Actual: fmt.Sprintf("%.2f%%", r.ErrorRate),
+			Passed: r.ErrorRate <= *t.ErrorRate,
+		})
+	}
+	return checks
+}
+
+// Violations returns a description of every threshold the result breaks, or
+// nil if it meets them all.
+func (r BenchmarkResult) Violations(t config.Thresholds) []string {
+	var violations []string
+	for _, c := range r.CheckThresholds(t) {
+		if !c.Passed {
+			violations = append(violations, c.String())
+		}
+	}
+	return violations
+}
+
+// ThresholdChecks checks the result against the thresholds of its config.
+func (r BenchmarkResult) ThresholdChecks() []ThresholdCheck {
+	if r.Config == nil || r.Config.Thresholds == nil {
+		return nil
+	}
+	return r.CheckThresholds(*r.Config.Thresholds)
+}
+
+// FailingError returns the run error that fails the result whatever its
+// thresholds, or nil. Failed requests alone do not fail a result whose
+// thresholds bound the error rate; that threshold decides.
+func (r BenchmarkResult) FailingError() error {
+	var requestErrors *RequestErrors
+	if errors.As(r.Error, &requestErrors) && r.Config != nil && r.Config.Thresholds != nil && r.Config.Thresholds.ErrorRate != nil {
+		return nil
+	}
+	return r.Error
+}
+
+// Passed reports whether the result has no failing error and meets every
+// threshold of its config.
+func (r BenchmarkResult) Passed() bool {
+	if r.FailingError() != nil {
+		return false
+	}
+	for _, c := range r.ThresholdChecks() {
+		if !c.Passed {
+			return false
+		}
+	}
+	return true
+}
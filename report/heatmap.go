@@ -0,0 +1,127 @@
+// Package report renders saved benchmark results for the browser.
+package report
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Th4phat/go-wrk/metrics"
+)
+
+// heatStops are the colors of the heatmap scale, from one request to the
+// busiest cell; cells in between are interpolated.
+var heatStops = [][3]float64{{68, 1, 84}, {59, 82, 139}, {33, 145, 140}, {94, 201, 98}, {253, 231, 37}}
+
+// heatColor returns the color of a cell with count requests when the busiest
+// cell has top. The scale is logarithmic so sparse outliers stay visible.
+func heatColor(count, top int) string {
+	f := 1.0
+	if top > 1 {
+		f = math.Log(float64(count)) / math.Log(float64(top))
+	}
+	pos := f * float64(len(heatStops)-1)
+	i := min(int(pos), len(heatStops)-2)
+	t := pos - float64(i)
+	var rgb [3]int
+	for k := range rgb {
+		rgb[k] = int(math.Round(heatStops[i][k] + (heatStops[i+1][k]-heatStops[i][k])*t))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2])
+}
+
+// HeatmapSVG renders the per-second latency histograms of a series as an SVG
+// heatmap: one column per second, one row per log-scaled latency bucket, and
+// the number of requests as the color. Hovering a cell shows its figures. It
+// returns "" if the series has no latencies.
+func HeatmapSVG(series []metrics.SeriesPoint) string {
+	lo, hi, ok := metrics.HistogramRange(series)
+	if !ok {
+		return ""
+	}
+	const (
+		left, top, bottom = 64, 8, 40
+		cellHeight        = 14
+	)
+	cellWidth := max(2, min(40, 900/len(series)))
+	plotWidth := cellWidth * len(series)
+	plotHeight := cellHeight * (hi - lo + 1)
+	width, height := max(left+plotWidth+16, 520), top+plotHeight+bottom
+
+	busiest := 0
+	for _, p := range series {
+		for _, n := range p.Histogram {
+			busiest = max(busiest, n)
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="%d" fill="#f4f4f4"/>`+"\n", left, top, plotWidth, plotHeight)
+	for c, p := range series {
+		for b := lo; b <= hi && b < len(p.Histogram); b++ {
+			n := p.Histogram[b]
+			if n == 0 {
+				continue
+			}
+			lower, upper := metrics.LatencyBucketBounds(b)
+			fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"><title>%s: %d requests %s-%s</title></rect>`+"\n",
+				left+c*cellWidth, top+(hi-b)*cellHeight, cellWidth, cellHeight, heatColor(n, busiest),
+				html.EscapeString(p.Offset.Round(time.Second).String()), n, metrics.FormatLatency(lower), metrics.FormatLatency(upper))
+		}
+	}
+
+	// Label every other bucket when they are dense, and about ten seconds.
+	labelEvery := 1
+	if hi-lo > 12 {
+		labelEvery = 2
+	}
+	for b := lo; b <= hi; b += labelEvery {
+		lower, _ := metrics.LatencyBucketBounds(b)
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" text-anchor="end">%s</text>`+"\n",
+			left-6, top+(hi-b+1)*cellHeight-3, metrics.FormatLatency(lower))
+	}
+	tickEvery := max(1, len(series)/10)
+	for c := 0; c < len(series); c += tickEvery {
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" text-anchor="middle">%s</text>`+"\n",
+			left+c*cellWidth+cellWidth/2, top+plotHeight+14, series[c].Offset.Round(time.Second))
+	}
+	fmt.Fprintf(&sb, `<text x="%d" y="%d">Time since start. Color: requests per second and bucket, 1 to %d (log scale).</text>`+"\n",
+		left, top+plotHeight+32, busiest)
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+var heatmapPage = template.Must(template.New("heatmap").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>go-wrk latency heatmap</title>
+<style>body{font-family:sans-serif;margin:2em;color:#222}h2{font-size:1.1em;margin-top:2em}p{color:#666}</style>
+</head>
+<body>
+<h1>Latency heatmap</h1>
+{{range .}}<h2>{{.Name}}</h2>
+{{if .SVG}}{{.SVG}}{{else}}<p>No per-second data was recorded.</p>{{end}}
+{{end}}</body>
+</html>
+`))
+
+// WriteHeatmapHTML writes a standalone HTML page with the latency heatmap of
+// every test of a run.
+func WriteHeatmapHTML(w io.Writer, run metrics.RunFile) error {
+	type section struct {
+		Name string
+		SVG  template.HTML
+	}
+	sections := make([]section, 0, len(run.Tests))
+	for _, t := range run.Tests {
+		sections = append(sections, section{Name: t.Name(), SVG: template.HTML(HeatmapSVG(t.Result.Series))})
+	}
+	return heatmapPage.Execute(w, sections)
+}
@@ -0,0 +1,142 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Th4phat/go-wrk/metrics"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut *junitText    `xml:"system-out,omitempty"`
+}
+
+type junitText struct {
+	Text string `xml:",cdata"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// WriteJUnit writes a run as JUnit XML: a suite per collection and a test
+// case per test. Broken thresholds are failures whose message holds the
+// measured values; a run error is an error.
+func WriteJUnit(w io.Writer, run metrics.RunFile) error {
+	suites := junitSuites{Name: "go-wrk"}
+	var total time.Duration
+	suiteIndex := map[string]int{}
+	var suiteTimes []time.Duration
+	for _, t := range run.Tests {
+		r := t.Result
+		collection := t.Collection
+		if collection == "" {
+			collection = "go-wrk"
+		}
+		name := t.Test
+		if name == "" {
+			name = t.Name()
+		}
+		c := junitCase{Name: name, Classname: collection, Time: seconds(r.TotalDuration), SystemOut: &junitText{resultText(r)}}
+
+		var violations []string
+		for _, check := range r.ThresholdChecks() {
+			if !check.Passed {
+				violations = append(violations, check.String())
+			}
+		}
+		if err := r.FailingError(); err != nil {
+			c.Error = &junitProblem{Message: err.Error(), Type: "run", Text: err.Error()}
+		} else if len(violations) > 0 {
+			c.Failure = &junitProblem{Message: strings.Join(violations, "; "), Type: "threshold", Text: strings.Join(violations, "\n")}
+		}
+
+		i, ok := suiteIndex[collection]
+		if !ok {
+			i = len(suites.Suites)
+			suiteIndex[collection] = i
+			suites.Suites = append(suites.Suites, junitSuite{Name: collection})
+			suiteTimes = append(suiteTimes, 0)
+		}
+		suite := &suites.Suites[i]
+		suite.Cases = append(suite.Cases, c)
+		suite.Tests++
+		suites.Tests++
+		if c.Failure != nil {
+			suite.Failures++
+			suites.Failures++
+		}
+		if c.Error != nil {
+			suite.Errors++
+			suites.Errors++
+		}
+		suiteTimes[i] += r.TotalDuration
+		total += r.TotalDuration
+	}
+	suites.Time = seconds(total)
+	for i, d := range suiteTimes {
+		suites.Suites[i].Time = seconds(d)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// resultText lists the main figures of a result, one per line.
+func resultText(r metrics.BenchmarkResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "requests: %d completed, %d errors (%.2f%%)\n", r.TotalRequestsCompleted, r.TotalErrors, r.ErrorRate)
+	fmt.Fprintf(&sb, "throughput: %.2f req/s\n", r.Throughput)
+	fmt.Fprintf(&sb, "latency: avg %s, p50 %s, p95 %s, p99 %s\n",
+		r.LatencyAvg.Round(time.Microsecond), r.LatencyP50.Round(time.Microsecond),
+		r.LatencyP95.Round(time.Microsecond), r.LatencyP99.Round(time.Microsecond))
+	for _, check := range r.ThresholdChecks() {
+		status := "pass"
+		if !check.Passed {
+			status = "FAIL"
+		}
+		fmt.Fprintf(&sb, "threshold %s <= %s: %s (%s)\n", check.Metric, check.Limit, check.Actual, status)
+	}
+	return sb.String()
+}
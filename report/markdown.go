@@ -0,0 +1,65 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Th4phat/go-wrk/metrics"
+)
+
+// WriteMarkdown writes a run as a Markdown summary, such as a GitHub Actions
+// step summary: a table of every test, then what failed.
+func WriteMarkdown(w io.Writer, run metrics.RunFile) error {
+	var sb strings.Builder
+	passed := 0
+	for _, t := range run.Tests {
+		if t.Result.Passed() {
+			passed++
+		}
+	}
+	sb.WriteString("## go-wrk results\n\n")
+	fmt.Fprintf(&sb, "%d of %d tests passed", passed, len(run.Tests))
+	if run.Environment != "" {
+		fmt.Fprintf(&sb, " in environment `%s`", run.Environment)
+	}
+	sb.WriteString(".\n\n")
+
+	sb.WriteString("| | Test | Requests | Throughput | p50 | p95 | p99 | Error rate |\n")
+	sb.WriteString("|---|---|---:|---:|---:|---:|---:|---:|\n")
+	for _, t := range run.Tests {
+		r := t.Result
+		status := "✅"
+		if !r.Passed() {
+			status = "❌"
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %d | %.1f req/s | %s | %s | %s | %.2f%% |\n", status, markdownEscape(t.Name()),
+			r.TotalRequestsCompleted, r.Throughput, r.LatencyP50.Round(time.Microsecond),
+			r.LatencyP95.Round(time.Microsecond), r.LatencyP99.Round(time.Microsecond), r.ErrorRate)
+	}
+
+	for _, t := range run.Tests {
+		r := t.Result
+		if r.Passed() {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n### ❌ %s\n\n", markdownEscape(t.Name()))
+		if err := r.FailingError(); err != nil {
+			fmt.Fprintf(&sb, "- %s\n", markdownEscape(err.Error()))
+		}
+		for _, check := range r.ThresholdChecks() {
+			if !check.Passed {
+				fmt.Fprintf(&sb, "- %s\n", check)
+			}
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// markdownEscape keeps text from breaking a table row or being read as
+// markup.
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`", "\n", " ").Replace(s)
+}
@@ -0,0 +1,279 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Th4phat/go-wrk/config"
+	"github.com/Th4phat/go-wrk/metrics"
+)
+
+// field is a labelled value of a table or metric tile.
+type field struct {
+	Name  string
+	Value string
+}
+
+type errorRow struct {
+	Kind    string
+	Count   int
+	Percent string
+}
+
+// testReport holds everything rendered for one test.
+type testReport struct {
+	Name        string
+	Passed      bool
+	Error       string
+	Config      []field
+	Metrics     []field
+	Phases      []field
+	Thresholds  []metrics.ThresholdCheck
+	Search      []metrics.SearchStep
+	Sustainable string
+	Charts      []template.HTML
+	Errors      []errorRow
+}
+
+// Write renders a saved run as a single HTML page. Styles are inline and the
+// charts are SVG, so the page needs no network access.
+func Write(w io.Writer, run metrics.RunFile) error {
+	data := struct {
+		Environment string
+		Tests       []testReport
+	}{Environment: run.Environment}
+	for _, t := range run.Tests {
+		data.Tests = append(data.Tests, newTestReport(t))
+	}
+	return reportPage.Execute(w, data)
+}
+
+func newTestReport(t metrics.TestResult) testReport {
+	r := t.Result
+	out := testReport{Name: t.Name(), Passed: r.Passed(), Thresholds: r.ThresholdChecks()}
+	if r.Error != nil {
+		out.Error = r.Error.Error()
+	}
+	if r.Config != nil {
+		out.Config = configFields(*r.Config)
+	}
+
+	var bytesIn, bytesOut int64
+	for _, p := range r.Series {
+		bytesIn += p.BytesIn
+		bytesOut += p.BytesOut
+	}
+	out.Metrics = []field{
+		{"Requests", fmt.Sprint(r.TotalRequestsCompleted)},
+		{"Errors", fmt.Sprintf("%d (%.2f%%)", r.TotalErrors, r.ErrorRate)},
+		{"Throughput", fmt.Sprintf("%.1f req/s", r.Throughput)},
+		{"Duration", r.TotalDuration.Round(time.Millisecond).String()},
+		{"Latency avg", r.LatencyAvg.Round(time.Microsecond).String()},
+		{"Latency p50", r.LatencyP50.Round(time.Microsecond).String()},
+		{"Latency p95", r.LatencyP95.Round(time.Microsecond).String()},
+		{"Latency p99", r.LatencyP99.Round(time.Microsecond).String()},
+	}
+	if len(r.Series) > 0 {
+		out.Metrics = append(out.Metrics, field{"Received", formatBytes(bytesIn)}, field{"Sent", formatBytes(bytesOut)})
+	}
+	if r.DroppedArrivals > 0 || r.LateArrivals > 0 {
+		out.Metrics = append(out.Metrics, field{"Dropped arrivals", fmt.Sprint(r.DroppedArrivals)}, field{"Late arrivals", fmt.Sprint(r.LateArrivals)})
+	}
+	for _, w := range []struct {
+		name  string
+		stats *metrics.PhaseStats
+	}{{"Warm-up", r.Warmup}, {"Cool-down", r.Cooldown}} {
+		if s := w.stats; s != nil {
+			out.Phases = append(out.Phases, field{w.name, fmt.Sprintf("%d requests, %d errors in %s, %.1f req/s, p50 %s, p99 %s",
+				s.RequestsCompleted, s.Errors, s.Duration.Round(time.Millisecond), s.Throughput,
+				s.LatencyP50.Round(time.Microsecond), s.LatencyP99.Round(time.Microsecond))})
+		}
+	}
+	if s := r.Search; s != nil {
+		out.Search = s.Steps
+		out.Sustainable = fmt.Sprintf("%.1f req/s (achieved %.1f req/s)", s.SustainableRate, s.SustainableThroughput)
+	}
+
+	hist := metrics.MeasuredHistogram(r.Series)
+	charts := []string{histogramSVG(hist), spectrumSVG(hist)}
+	if n := len(r.Series); n > 0 {
+		rps := make([]float64, n)
+		errRate := make([]float64, n)
+		p50 := make([]float64, n)
+		p90 := make([]float64, n)
+		p99 := make([]float64, n)
+		for i, p := range r.Series {
+			rps[i] = p.Throughput
+			errRate[i] = p.ErrorRate()
+			p50[i] = float64(p.LatencyP50) / float64(time.Millisecond)
+			p90[i] = float64(p.LatencyP90) / float64(time.Millisecond)
+			p99[i] = float64(p.LatencyP99) / float64(time.Millisecond)
+		}
+		last := r.Series[n-1]
+		span := last.Offset + last.Duration
+		charts = append(charts,
+			lineChartSVG("Throughput (req/s)", span, []chartLine{{"req/s", "#5ec962", rps}}, func(v float64) string { return fmt.Sprintf("%.0f", v) }),
+			lineChartSVG("Error rate", span, []chartLine{{"errors", "#d62728", errRate}}, func(v float64) string { return fmt.Sprintf("%.3g%%", v) }),
+			lineChartSVG("Latency", span, []chartLine{{"p50", "#21918c", p50}, {"p90", "#3b528b", p90}, {"p99", "#c2185b", p99}},
+				func(v float64) string { return fmt.Sprintf("%.3gms", v) }),
+			HeatmapSVG(r.Series))
+	}
+	for _, c := range charts {
+		if c != "" {
+			out.Charts = append(out.Charts, template.HTML(c))
+		}
+	}
+
+	for kind, count := range r.ErrorDetails {
+		percent := ""
+		if r.TotalErrors > 0 {
+			percent = fmt.Sprintf("%.1f%%", float64(count)/float64(r.TotalErrors)*100)
+		}
+		out.Errors = append(out.Errors, errorRow{kind, count, percent})
+	}
+	sort.Slice(out.Errors, func(i, j int) bool {
+		if out.Errors[i].Count != out.Errors[j].Count {
+			return out.Errors[i].Count > out.Errors[j].Count
+		}
+		return out.Errors[i].Kind < out.Errors[j].Kind
+	})
+	return out
+}
+
+// configFields summarizes what a test sent and how.
+func configFields(c config.BenchmarkConfig) []field {
+	method := c.Method
+	if method == "" {
+		method = "GET"
+	}
+	fields := []field{{"Request", method + " " + c.TargetURL}}
+	if len(c.Mix) > 0 {
+		fields = append(fields, field{"Request mix", fmt.Sprintf("%d weighted requests", len(c.Mix))})
+	}
+	switch {
+	case c.Users != nil:
+		fields = append(fields, field{"Load", fmt.Sprintf("%d virtual users", c.Users.Count)})
+	case c.Search != nil:
+		fields = append(fields, field{"Load", fmt.Sprintf("capacity search from %g req/s, SLO %s", c.Search.StartRate, c.Search.SLO)})
+	case c.Arrivals != nil:
+		fields = append(fields, field{"Load", c.Arrivals.String()})
+	case c.Adaptive != nil:
+		fields = append(fields, field{"Load", "adaptive concurrency: " + c.Adaptive.String()})
+	default:
+		fields = append(fields, field{"Load", fmt.Sprintf("%d threads", c.Threads)})
+	}
+	fields = append(fields, field{"Connections", fmt.Sprint(c.Connections)}, field{"Duration", c.Duration})
+	if c.Warmup != "" || c.Cooldown != "" {
+		fields = append(fields, field{"Warm-up / cool-down", fmt.Sprintf("%s / %s", orNone(c.Warmup), orNone(c.Cooldown))})
+	}
+	if len(c.Headers) > 0 {
+		names := make([]string, 0, len(c.Headers))
+		for name := range c.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fields = append(fields, field{"Headers", strings.Join(names, ", ")})
+	}
+	if c.HasBody() {
+		fields = append(fields, field{"Body", c.BodyType})
+	}
+	if c.SkipVerify() {
+		fields = append(fields, field{"TLS verification", "disabled"})
+	}
+	return fields
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+var reportPage = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>go-wrk report</title>
+<style>
+body{font-family:-apple-system,"Segoe UI",Helvetica,Arial,sans-serif;margin:0;color:#222;background:#fafafa}
+header{background:#263238;color:#fff;padding:1em 2em}
+header h1{margin:0;font-size:1.4em}
+nav a{color:#b0bec5;margin-right:1em}
+section.test{background:#fff;margin:1.5em 2em;padding:1em 1.5em;border-radius:6px;box-shadow:0 1px 3px rgba(0,0,0,.12)}
+h2{margin-top:0}
+h3{font-size:1em;color:#555;margin-top:1.5em}
+.badge{font-size:.75em;padding:.2em .6em;border-radius:1em;vertical-align:middle;margin-left:.5em}
+.pass{background:#e8f5e9;color:#2e7d32}
+.fail{background:#ffebee;color:#c62828}
+.tiles{display:flex;flex-wrap:wrap;gap:.8em}
+.tile{border:1px solid #e0e0e0;border-radius:4px;padding:.5em .8em;min-width:8em}
+.tile .name{font-size:.8em;color:#777}
+.tile .value{font-size:1.2em;font-weight:bold}
+table{border-collapse:collapse;font-size:.9em}
+td,th{padding:.3em .8em;border-bottom:1px solid #eee;text-align:left}
+th{color:#555}
+.error{color:#c62828}
+.charts svg{display:block;margin:1em 0;max-width:100%;height:auto}
+</style>
+</head>
+<body>
+<header>
+<h1>go-wrk report</h1>
+{{if .Environment}}<p>Environment: {{.Environment}}</p>{{end}}
+{{if gt (len .Tests) 1}}<nav>{{range $i, $t := .Tests}}<a href="#test-{{$i}}">{{$t.Name}}</a>{{end}}</nav>{{end}}
+</header>
+{{range $i, $t := .Tests}}
+<section class="test" id="test-{{$i}}">
+<h2>{{.Name}}{{if .Passed}}<span class="badge pass">passed</span>{{else}}<span class="badge fail">failed</span>{{end}}</h2>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+
+<h3>Key metrics</h3>
+<div class="tiles">{{range .Metrics}}<div class="tile"><div class="name">{{.Name}}</div><div class="value">{{.Value}}</div></div>{{end}}</div>
+{{if .Phases}}<p>Excluded from the statistics:</p>
+<table>{{range .Phases}}<tr><th>{{.Name}}</th><td>{{.Value}}</td></tr>{{end}}</table>{{end}}
+
+{{if .Config}}<h3>Configuration</h3>
+<table>{{range .Config}}<tr><th>{{.Name}}</th><td>{{.Value}}</td></tr>{{end}}</table>{{end}}
+
+{{if .Thresholds}}<h3>Thresholds</h3>
+<table><tr><th>Metric</th><th>Limit</th><th>Actual</th><th></th></tr>
+{{range .Thresholds}}<tr><td>{{.Metric}}</td><td>{{.Limit}}</td><td>{{.Actual}}</td><td>{{if .Passed}}<span class="badge pass">pass</span>{{else}}<span class="badge fail">fail</span>{{end}}</td></tr>
+{{end}}</table>{{end}}
+
+{{if .Search}}<h3>Capacity search</h3>
+<p>Sustainable rate: {{.Sustainable}}</p>
+<table><tr><th>Rate</th><th>Throughput</th><th>p99</th><th>Error rate</th><th></th></tr>
+{{range .Search}}<tr><td>{{printf "%.1f" .Rate}}</td><td>{{printf "%.1f" .Throughput}}</td><td>{{.LatencyP99}}</td><td>{{printf "%.2f%%" .ErrorRate}}</td><td>{{if .Passed}}<span class="badge pass">pass</span>{{else}}<span class="badge fail">{{range .Violations}}{{.}}; {{end}}</span>{{end}}</td></tr>
+{{end}}</table>{{end}}
+
+{{if .Charts}}<h3>Charts</h3>
+<div class="charts">{{range .Charts}}{{.}}{{end}}</div>{{end}}
+
+{{if .Errors}}<h3>Errors</h3>
+<table><tr><th>Error</th><th>Count</th><th>Share</th></tr>
+{{range .Errors}}<tr><td class="error">{{.Kind}}</td><td>{{.Count}}</td><td>{{.Percent}}</td></tr>
+{{end}}</table>{{end}}
+</section>
+{{end}}
+</body>
+</html>
+`))
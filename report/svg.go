@@ -0,0 +1,161 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Th4phat/go-wrk/metrics"
+)
+
+// Size of the charts' plot areas, and the room around them for labels.
+const (
+	chartWidth, chartHeight = 640, 180
+	marginLeft, marginTop   = 64, 24
+	marginRight, marginBot  = 16, 36
+)
+
+// svgOpen starts a chart with its title and the y-axis grid, labelled from 0
+// to top.
+func svgOpen(sb *strings.Builder, title string, top float64, yLabel func(float64) string) {
+	width, height := marginLeft+chartWidth+marginRight, marginTop+chartHeight+marginBot
+	fmt.Fprintf(sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(sb, `<text x="%d" y="14" font-size="13" font-weight="bold">%s</text>`+"\n", marginLeft, html.EscapeString(title))
+	const gridLines = 4
+	for i := 0; i <= gridLines; i++ {
+		y := marginTop + chartHeight - chartHeight*i/gridLines
+		fmt.Fprintf(sb, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#ddd"/>`+"\n", marginLeft, y, marginLeft+chartWidth, y)
+		fmt.Fprintf(sb, `<text x="%d" y="%d" text-anchor="end">%s</text>`+"\n",
+			marginLeft-6, y+4, html.EscapeString(yLabel(top*float64(i)/gridLines)))
+	}
+}
+
+// chartLine is one line of a line chart.
+type chartLine struct {
+	name   string
+	color  string
+	values []float64
+}
+
+// lineChartSVG plots lines over the span of a run, one value per second.
+func lineChartSVG(title string, span time.Duration, lines []chartLine, yLabel func(float64) string) string {
+	var top float64
+	points := 0
+	for _, l := range lines {
+		for _, v := range l.values {
+			top = math.Max(top, v)
+		}
+		points = max(points, len(l.values))
+	}
+	if points < 2 {
+		return ""
+	}
+	top = metrics.NiceCeil(top)
+
+	var sb strings.Builder
+	svgOpen(&sb, title, top, yLabel)
+	seconds := math.Max(span.Seconds(), 1)
+	tickEvery := metrics.NiceCeil(seconds / 8)
+	for t := 0.0; t <= seconds; t += tickEvery {
+		x := marginLeft + int(t/seconds*chartWidth)
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" text-anchor="middle">%s</text>`+"\n",
+			x, marginTop+chartHeight+14, time.Duration(t*float64(time.Second)))
+	}
+	for i, l := range lines {
+		coords := make([]string, len(l.values))
+		for j, v := range l.values {
+			x := float64(marginLeft) + float64(j)/float64(points-1)*chartWidth
+			y := float64(marginTop+chartHeight) - v/top*chartHeight
+			coords[j] = fmt.Sprintf("%.1f,%.1f", x, y)
+		}
+		fmt.Fprintf(&sb, `<polyline fill="none" stroke="%s" stroke-width="1.5" points="%s"/>`+"\n", l.color, strings.Join(coords, " "))
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" fill="%s">%s</text>`+"\n",
+			marginLeft+chartWidth-60*(len(lines)-i), marginTop+chartHeight+30, l.color, html.EscapeString(l.name))
+	}
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+// requestCount returns the number of requests in a latency histogram.
+func requestCount(counts []int) int {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
+
+// histogramSVG draws the number of requests per log-scaled latency bucket.
+func histogramSVG(counts []int) string {
+	total := requestCount(counts)
+	lo := 0
+	for lo < len(counts) && counts[lo] == 0 {
+		lo++
+	}
+	if lo == len(counts) {
+		return ""
+	}
+	counts = counts[lo:]
+	busiest := 0
+	for _, n := range counts {
+		busiest = max(busiest, n)
+	}
+	top := metrics.NiceCeil(float64(busiest))
+
+	var sb strings.Builder
+	svgOpen(&sb, "Latency distribution (requests per bucket, log-scaled buckets)", top, func(v float64) string { return fmt.Sprintf("%.0f", v) })
+	barWidth := chartWidth / len(counts)
+	for i, n := range counts {
+		lower, upper := metrics.LatencyBucketBounds(lo + i)
+		h := int(float64(n) / top * chartHeight)
+		x := marginLeft + i*barWidth
+		fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="%d" fill="#3b528b"><title>%s-%s: %d requests (%.2f%%)</title></rect>`+"\n",
+			x+1, marginTop+chartHeight-h, max(barWidth-2, 1), h,
+			metrics.FormatLatency(lower), metrics.FormatLatency(upper), n, float64(n)/float64(total)*100)
+		if i%max(1, len(counts)/8) == 0 {
+			fmt.Fprintf(&sb, `<text x="%d" y="%d">%s</text>`+"\n", x, marginTop+chartHeight+14, metrics.FormatLatency(lower))
+		}
+	}
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+// spectrumPercentiles are the points of the percentile spectrum.
+var spectrumPercentiles = []float64{0, 10, 20, 30, 40, 50, 60, 70, 75, 80, 85, 90, 92.5, 95, 96, 97, 98, 99, 99.5, 99.9, 99.95, 99.99, 99.999, 100}
+
+// spectrumSVG plots latency by percentile, estimated from a latency
+// histogram, with the x-axis stretched towards the tail: each tick is one
+// more nine.
+func spectrumSVG(counts []int) string {
+	total := requestCount(counts)
+	if total < 2 {
+		return ""
+	}
+	const nines = 5 // The axis ends at 99.999%
+	xOf := func(p float64) float64 {
+		n := math.Min(-math.Log10(math.Max(1-p/100, math.Pow(10, -nines))), nines)
+		return float64(marginLeft) + n/nines*chartWidth
+	}
+	top := metrics.NiceCeil(float64(metrics.HistogramPercentile(counts, 100)) / float64(time.Millisecond))
+
+	var sb strings.Builder
+	svgOpen(&sb, "Percentile spectrum", top, func(v float64) string { return fmt.Sprintf("%gms", v) })
+	for i, label := range []string{"0%", "90%", "99%", "99.9%", "99.99%", "99.999%"} {
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" text-anchor="middle">%s</text>`+"\n",
+			marginLeft+i*chartWidth/nines, marginTop+chartHeight+14, label)
+	}
+	var coords []string
+	for _, p := range spectrumPercentiles {
+		d := metrics.HistogramPercentile(counts, p)
+		x, y := xOf(p), float64(marginTop+chartHeight)-float64(d)/float64(time.Millisecond)/top*chartHeight
+		coords = append(coords, fmt.Sprintf("%.1f,%.1f", x, y))
+		fmt.Fprintf(&sb, `<circle cx="%.1f" cy="%.1f" r="3" fill="#21918c"><title>p%g: %s</title></circle>`+"\n",
+			x, y, p, d.Round(time.Microsecond))
+	}
+	fmt.Fprintf(&sb, `<polyline fill="none" stroke="#21918c" stroke-width="1.5" points="%s"/>`+"\n", strings.Join(coords, " "))
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
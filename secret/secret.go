@@ -0,0 +1,113 @@
+// Package secret resolves secret references in test configs. Test files hold
+// only the references, which are replaced with their values when a benchmark
+// starts:
+//
+//	${env:API_TOKEN}           the environment variable API_TOKEN
+//	${file:/run/secrets/token} the contents of a file, without trailing newlines
+//	${secret:prod_token}       a value from the encrypted secrets store
+package secret
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/Th4phat/go-wrk/config"
+)
+
+var refPattern = regexp.MustCompile(`\$\{(env|file|secret):([^{}]+)\}`)
+
+// HasReferences reports whether s contains a secret reference.
+func HasReferences(s string) bool {
+	return refPattern.MatchString(s)
+}
+
+// Resolver replaces secret references with their values. The secrets store
+// is only opened if a ${secret:...} reference is used.
+type Resolver struct {
+	StorePath  string
+	Passphrase string
+
+	store *Store
+}
+
+// NewResolver returns a resolver for the store in the config directory,
+// unlocked with the passphrase from the GOWRK_SECRETS_PASSPHRASE variable.
+func NewResolver() *Resolver {
+	return &Resolver{StorePath: StorePath(), Passphrase: os.Getenv(PassphraseEnv)}
+}
+
+// ResolveConfig returns a copy of cfg with every secret reference replaced by
+// its value, using a resolver from NewResolver.
+func ResolveConfig(cfg config.BenchmarkConfig) (config.BenchmarkConfig, error) {
+	return NewResolver().ResolveConfig(cfg)
+}
+
+// ResolveConfig returns a copy of cfg with every secret reference in its
+// URLs, headers and payloads replaced by its value. cfg itself is unchanged.
+func (r *Resolver) ResolveConfig(cfg config.BenchmarkConfig) (config.BenchmarkConfig, error) {
+	var firstErr error
+	resolved := cfg.MapValues(func(s string) string {
+		v, err := r.Resolve(s)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return v
+	})
+	return resolved, firstErr
+}
+
+// Resolve replaces the secret references in s.
+func (r *Resolver) Resolve(s string) (string, error) {
+	if !HasReferences(s) {
+		return s, nil
+	}
+	var firstErr error
+	out := refPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		m := refPattern.FindStringSubmatch(ref)
+		v, err := r.lookup(m[1], strings.TrimSpace(m[2]))
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return ref
+		}
+		return v
+	})
+	return out, firstErr
+}
+
+func (r *Resolver) lookup(kind, name string) (string, error) {
+	switch kind {
+	case "env":
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("secret ${env:%s}: environment variable is not set", name)
+		}
+		return v, nil
+	case "file":
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return "", fmt.Errorf("secret ${file:%s}: %w", name, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case "secret":
+		if r.store == nil {
+			if _, err := os.Stat(r.StorePath); err != nil {
+				return "", fmt.Errorf("secret ${secret:%s}: no secrets store at %s; add one with 'go-wrk secrets set'", name, r.StorePath)
+			}
+			store, err := OpenStore(r.StorePath, r.Passphrase)
+			if err != nil {
+				return "", fmt.Errorf("secret ${secret:%s}: %w", name, err)
+			}
+			r.store = store
+		}
+		v, ok := r.store.Get(name)
+		if !ok {
+			return "", fmt.Errorf("secret ${secret:%s}: not in the secrets store", name)
+		}
+		return v, nil
+	}
+	return "", fmt.Errorf("unknown secret reference kind %q", kind)
+}
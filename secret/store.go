@@ -0,0 +1,174 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Th4phat/go-wrk/config"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// PassphraseEnv names the environment variable holding the passphrase of
+	// the secrets store.
+	PassphraseEnv = "GOWRK_SECRETS_PASSPHRASE"
+
+	storeFileName = "secrets.enc"
+	storeVersion  = 1
+	kdfName       = "pbkdf2-sha256"
+	kdfIterations = 600000
+	keyLen        = 32 // AES-256
+)
+
+// ErrWrongPassphrase is returned when the store cannot be decrypted.
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted secrets file")
+
+// storeFile is the on-disk form of the store: the secrets as a JSON object,
+// sealed with AES-256-GCM under a key derived from the passphrase.
+type storeFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Store is a passphrase-encrypted set of named secrets.
+type Store struct {
+	path       string
+	passphrase string
+	values     map[string]string
+}
+
+// StorePath returns the location of the secrets store in the config directory.
+func StorePath() string {
+	return filepath.Join(config.GetConfigDir(), storeFileName)
+}
+
+// OpenStore decrypts the store at path. A missing file is an empty store.
+func OpenStore(path, passphrase string) (*Store, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("no passphrase for the secrets store; set %s", PassphraseEnv)
+	}
+	s := &Store{path: path, passphrase: passphrase, values: make(map[string]string)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading secrets store: %w", err)
+	}
+	var file storeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing secrets store %s: %w", path, err)
+	}
+	if file.Version != storeVersion || file.KDF != kdfName {
+		return nil, fmt.Errorf("secrets store %s has unsupported format (version %d, kdf %q)", path, file.Version, file.KDF)
+	}
+
+	gcm, err := newGCM(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Nonce) != gcm.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plain, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	if err := json.Unmarshal(plain, &s.values); err != nil {
+		return nil, fmt.Errorf("decoding secrets: %w", err)
+	}
+	return s, nil
+}
+
+// Get returns the named secret.
+func (s *Store) Get(name string) (string, bool) {
+	v, ok := s.values[name]
+	return v, ok
+}
+
+// Set adds or replaces a secret. Call Save to persist it.
+func (s *Store) Set(name, value string) {
+	s.values[name] = value
+}
+
+// Delete removes a secret and reports whether it existed.
+func (s *Store) Delete(name string) bool {
+	_, ok := s.values[name]
+	delete(s.values, name)
+	return ok
+}
+
+// Names returns the sorted secret names.
+func (s *Store) Names() []string {
+	names := make([]string, 0, len(s.values))
+	for name := range s.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Save encrypts the store with a fresh salt and nonce and writes it, readable
+// only by the current user.
+func (s *Store) Save() error {
+	plain, err := json.Marshal(s.values)
+	if err != nil {
+		return fmt.Errorf("encoding secrets: %w", err)
+	}
+	file := storeFile{Version: storeVersion, KDF: kdfName, Iterations: kdfIterations, Salt: make([]byte, 16)}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return fmt.Errorf("generating salt: %w", err)
+	}
+	gcm, err := newGCM(s.passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return fmt.Errorf("generating nonce: %w", err)
+	}
+	file.Ciphertext = gcm.Seal(nil, file.Nonce, plain, nil)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding secrets store: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+	// Write then rename so an interrupted save cannot lose the store.
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("writing secrets store: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("writing secrets store: %w", err)
+	}
+	return nil
+}
+
+func newGCM(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	if iterations <= 0 || len(salt) == 0 {
+		return nil, fmt.Errorf("secrets store has invalid key derivation parameters")
+	}
+	key := pbkdf2.Key([]byte(passphrase), salt, iterations, keyLen, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
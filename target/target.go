@@ -0,0 +1,250 @@
+package target
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Latency distributions supported by the target server.
+const (
+	DistFixed       = "fixed"
+	DistUniform     = "uniform"
+	DistNormal      = "normal"
+	DistExponential = "exponential"
+)
+
+// Options configures the behaviour of the local target server.
+type Options struct {
+	Addr string
+
+	// Latency is the base artificial latency added to every response. Its
+	// meaning depends on Distribution: the constant delay for fixed, the mean
+	// for normal and exponential, and the lower bound for uniform.
+	Latency      time.Duration
+	Jitter       time.Duration // Upper spread for uniform, standard deviation for normal
+	Distribution string
+
+	ResponseSize int     // Size of the response body in bytes
+	ErrorPercent float64 // Percentage of requests answered with HTTP 500
+
+	// StatusMix maps status codes to relative weights for non-error responses.
+	// An empty mix always answers 200.
+	StatusMix map[int]int
+}
+
+type statusWeight struct {
+	code  int
+	cumul int
+}
+
+// Server is a small HTTP server with configurable latency, size and errors,
+// used to measure the client's own ceiling.
+type Server struct {
+	opts     Options
+	body     []byte
+	statuses []statusWeight
+	total    int
+
+	server   *fasthttp.Server
+	listener net.Listener
+
+	rndPool sync.Pool
+}
+
+// New validates the options and prepares a server. Call Start or Serve to run it.
+func New(opts Options) (*Server, error) {
+	if opts.Distribution == "" {
+		opts.Distribution = DistFixed
+	}
+	switch opts.Distribution {
+	case DistFixed, DistUniform, DistNormal, DistExponential:
+	default:
+		return nil, fmt.Errorf("unknown latency distribution %q", opts.Distribution)
+	}
+	if opts.Latency < 0 || opts.Jitter < 0 {
+		return nil, fmt.Errorf("latency and jitter cannot be negative")
+	}
+	if opts.ResponseSize < 0 {
+		return nil, fmt.Errorf("response size cannot be negative")
+	}
+	if opts.ErrorPercent < 0 || opts.ErrorPercent > 100 {
+		return nil, fmt.Errorf("error percentage must be between 0 and 100")
+	}
+
+	s := &Server{opts: opts}
+	s.body = make([]byte, opts.ResponseSize)
+	for i := range s.body {
+		s.body[i] = 'a' + byte(i%26)
+	}
+
+	codes := make([]int, 0, len(opts.StatusMix))
+	for code := range opts.StatusMix {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		w := opts.StatusMix[code]
+		if code < 100 || code > 999 {
+			return nil, fmt.Errorf("invalid status code %d in status mix", code)
+		}
+		if w < 0 {
+			return nil, fmt.Errorf("negative weight for status %d", code)
+		}
+		if w == 0 {
+			continue
+		}
+		s.total += w
+		s.statuses = append(s.statuses, statusWeight{code: code, cumul: s.total})
+	}
+
+	s.rndPool.New = func() any {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	s.server = &fasthttp.Server{
+		Handler:                       s.handle,
+		Name:                          "go-wrk-target",
+		NoDefaultDate:                 true,
+		NoDefaultContentType:          true,
+		DisableHeaderNamesNormalizing: true,
+	}
+	return s, nil
+}
+
+func (s *Server) listen() error {
+	addr := s.opts.Addr
+	if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", addr, err)
+	}
+	s.listener = ln
+	return nil
+}
+
+// Start listens on the configured address and serves in the background.
+func (s *Server) Start() error {
+	if err := s.listen(); err != nil {
+		return err
+	}
+	go s.server.Serve(s.listener)
+	return nil
+}
+
+// Serve listens on the configured address and blocks until the server stops.
+func (s *Server) Serve() error {
+	if err := s.listen(); err != nil {
+		return err
+	}
+	return s.server.Serve(s.listener)
+}
+
+// Addr returns the address the server is listening on, once started.
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return s.opts.Addr
+	}
+	return s.listener.Addr().String()
+}
+
+// URL returns an http URL pointing at the running server.
+func (s *Server) URL() string {
+	addr := s.Addr()
+	host, port, err := net.SplitHostPort(addr)
+	if err == nil && (host == "" || host == "::" || host == "0.0.0.0") {
+		addr = net.JoinHostPort("127.0.0.1", port)
+	}
+	return "http://" + addr + "/"
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	return s.server.Shutdown()
+}
+
+func (s *Server) handle(ctx *fasthttp.RequestCtx) {
+	rnd := s.rndPool.Get().(*rand.Rand)
+	delay := s.delay(rnd)
+	status := s.status(rnd)
+	s.rndPool.Put(rnd)
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	ctx.SetStatusCode(status)
+	ctx.SetContentType("text/plain")
+	if !ctx.IsHead() {
+		ctx.SetBody(s.body)
+	}
+}
+
+func (s *Server) delay(rnd *rand.Rand) time.Duration {
+	base := float64(s.opts.Latency)
+	var d float64
+	switch s.opts.Distribution {
+	case DistUniform:
+		d = base + rnd.Float64()*float64(s.opts.Jitter)
+	case DistNormal:
+		d = base + rnd.NormFloat64()*float64(s.opts.Jitter)
+	case DistExponential:
+		d = rnd.ExpFloat64() * base
+	default:
+		d = base
+	}
+	if d < 0 || math.IsNaN(d) {
+		return 0
+	}
+	return time.Duration(d)
+}
+
+func (s *Server) status(rnd *rand.Rand) int {
+	if s.opts.ErrorPercent > 0 && rnd.Float64()*100 < s.opts.ErrorPercent {
+		return fasthttp.StatusInternalServerError
+	}
+	if s.total == 0 {
+		return fasthttp.StatusOK
+	}
+	n := rnd.Intn(s.total)
+	for _, sw := range s.statuses {
+		if n < sw.cumul {
+			return sw.code
+		}
+	}
+	return fasthttp.StatusOK
+}
+
+// ParseStatusMix parses a mix like "200:90,404:5,503:5" into code weights.
+func ParseStatusMix(s string) (map[int]int, error) {
+	mix := make(map[int]int)
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return mix, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		codeStr, weightStr, found := strings.Cut(part, ":")
+		if !found {
+			weightStr = "1"
+		}
+		code, err := strconv.Atoi(strings.TrimSpace(codeStr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code in %q: %w", part, err)
+		}
+		weight, err := strconv.Atoi(strings.TrimSpace(weightStr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight in %q: %w", part, err)
+		}
+		mix[code] += weight
+	}
+	return mix, nil
+}
@@ -0,0 +1,241 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Th4phat/go-wrk/config"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// payloadInput is the focus index of the payload editor, after the four
+// single-line inputs. Every method may send a payload.
+const (
+	payloadInput    = 4
+	numConfigInputs = payloadInput + 1
+)
+
+// bodyType returns the selected body type, defaulting to JSON.
+func (m Model) bodyType() string {
+	if m.baseConfig.BodyType == "" {
+		return config.BodyJSON
+	}
+	return m.baseConfig.BodyType
+}
+
+// cycleBodyType selects the next body type.
+func (m *Model) cycleBodyType() {
+	current := m.bodyType()
+	next := config.BodyTypes[0]
+	for i, t := range config.BodyTypes {
+		if t == current {
+			next = config.BodyTypes[(i+1)%len(config.BodyTypes)]
+		}
+	}
+	m.baseConfig.BodyType = next
+	if next == config.BodyJSON {
+		m.baseConfig.BodyType = ""
+	}
+	m.addLog(fmt.Sprintf("Body type: %s (%s)", next, config.BodyContentType(next)))
+}
+
+// jsonSyntaxError returns the location and cause of a JSON syntax error in s,
+// or nil if s is empty or valid JSON.
+func jsonSyntaxError(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	var v any
+	err := json.Unmarshal([]byte(s), &v)
+	if err == nil {
+		return nil
+	}
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return err
+	}
+	line, column := 1, 1
+	for _, r := range s[:min(int(syntaxErr.Offset), len(s))] {
+		if r == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	// The offset is just past the offending byte.
+	if column > 1 {
+		column--
+	}
+	return fmt.Errorf("line %d, column %d: %v", line, column, syntaxErr)
+}
+
+// parseFormLines parses the editor's text as form fields, one per line in
+// curl's -F syntax. Blank lines are skipped.
+func parseFormLines(s string) ([]config.FormField, error) {
+	var fields []config.FormField
+	for i, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		field, err := config.ParseFormField(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// formLines returns the text the editor shows for a form body: its fields,
+// or an encoded form payload split into one field per line.
+func formLines(cfg config.BenchmarkConfig) string {
+	var lines []string
+	for _, f := range cfg.Form {
+		lines = append(lines, f.String())
+	}
+	if len(cfg.Form) == 0 && cfg.Payload != "" {
+		for _, pair := range strings.Split(cfg.Payload, "&") {
+			if decoded, err := url.QueryUnescape(pair); err == nil {
+				pair = decoded
+			}
+			lines = append(lines, pair)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// payloadError returns the problem with the payload being edited: a JSON
+// syntax error, or a malformed form field.
+func (m Model) payloadError() error {
+	if m.baseConfig.PayloadFile != "" {
+		return nil
+	}
+	switch bodyType := m.bodyType(); {
+	case bodyType == config.BodyJSON:
+		if err := jsonSyntaxError(m.requestPayload.Value()); err != nil {
+			return fmt.Errorf("JSON %w", err)
+		}
+	case config.IsFormBody(bodyType):
+		if _, err := parseFormLines(m.requestPayload.Value()); err != nil {
+			return fmt.Errorf("form %w", err)
+		}
+	}
+	return nil
+}
+
+// reformatPayload pretty-prints or minifies the JSON payload.
+func (m *Model) reformatPayload(pretty bool) {
+	payload := m.requestPayload.Value()
+	if strings.TrimSpace(payload) == "" {
+		return
+	}
+	if m.bodyType() != config.BodyJSON {
+		m.configError = "Formatting applies to JSON payloads only."
+		return
+	}
+	if err := jsonSyntaxError(payload); err != nil {
+		m.configError = fmt.Sprintf("Cannot format payload: %v", err)
+		m.addLog(m.configError)
+		return
+	}
+	var buf bytes.Buffer
+	if pretty {
+		_ = json.Indent(&buf, []byte(payload), "", "  ")
+	} else {
+		_ = json.Compact(&buf, []byte(payload))
+	}
+	m.requestPayload.SetValue(buf.String())
+	m.configError = ""
+}
+
+func (m *Model) startLoadingBody() tea.Cmd {
+	m.bodyFileInput.SetValue(m.baseConfig.PayloadFile)
+	m.bodyFileInput.CursorEnd()
+	m.bodyFileError = ""
+	m.status = StatusLoadingBody
+	return textinput.Blink
+}
+
+func (m *Model) handleLoadingBodyKeys(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.keys.Enter):
+		path := strings.TrimSpace(m.bodyFileInput.Value())
+		if path == "" {
+			m.baseConfig.PayloadFile = ""
+			m.status = StatusIdle
+			m.addLog("Payload file cleared.")
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			m.bodyFileError = err.Error()
+			return textinput.Blink
+		}
+		// Binary files are sent as they are read at run time; text files are
+		// copied into the editor.
+		if m.bodyType() == config.BodyBinary || !utf8.Valid(data) {
+			m.baseConfig.PayloadFile = path
+			m.requestPayload.SetValue("")
+			m.addLog(fmt.Sprintf("Payload will be read from %s (%d bytes).", path, len(data)))
+		} else {
+			m.baseConfig.PayloadFile = ""
+			m.requestPayload.SetValue(string(data))
+			m.addLog(fmt.Sprintf("Loaded payload from %s (%d bytes).", path, len(data)))
+		}
+		m.status = StatusIdle
+		m.focusedInput = payloadInput
+	case key.Matches(msg, m.keys.Back):
+		m.status = StatusIdle
+		m.addLog("Loading payload cancelled.")
+	}
+	return nil
+}
+
+func (m Model) viewLoadingBody() string {
+	b := strings.Builder{}
+	b.WriteString("Load Payload from File\n\n")
+	b.WriteString(m.bodyFileInput.View() + "\n\n")
+	if m.bodyFileError != "" {
+		b.WriteString(errorStyle.Render(m.bodyFileError) + "\n")
+	}
+	b.WriteString(fmt.Sprintf("Text files are copied into the editor; with the %s body type the file is sent as is.\n", config.BodyBinary))
+	b.WriteString("Press Enter to load (an empty path clears the file), Esc to cancel.")
+	return panelStyle.Width(m.windowWidth - 4).Render(b.String())
+}
+
+// viewPayload renders the payload editor with its body type and any problem
+// with its content.
+func (m Model) viewPayload() string {
+	b := strings.Builder{}
+	if config.IsFormBody(m.bodyType()) {
+		b.WriteString(fmt.Sprintf("Payload (%s, one field per line: name=value", m.bodyType()))
+		if m.bodyType() == config.BodyMultipart {
+			b.WriteString(" or name=@file;type=content/type")
+		}
+		b.WriteString("):\n")
+	} else {
+		b.WriteString(fmt.Sprintf("Payload (%s):\n", m.bodyType()))
+	}
+	if m.baseConfig.PayloadFile != "" {
+		b.WriteString(fmt.Sprintf("File: %s\n", m.baseConfig.PayloadFile))
+	} else {
+		b.WriteString(m.requestPayload.View() + "\n")
+	}
+	if err := m.payloadError(); err != nil {
+		b.WriteString(errorStyle.Render(err.Error()) + "\n")
+	}
+	if m.focusedInput == payloadInput {
+		b.WriteString(placeholderStyle.Render("Ctrl+F: format JSON, Ctrl+T: minify, Ctrl+O: load file, Ctrl+B: body type, Tab/Esc: leave editor") + "\n")
+	}
+	return b.String()
+}
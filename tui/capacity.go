@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Th4phat/go-wrk/metrics"
+)
+
+// searchState returns the capacity search being shown: the final outcome once
+// the run is over, otherwise the latest progress. It is nil outside search
+// mode.
+func (m Model) searchState() *metrics.SearchResult {
+	if (m.status == StatusCompleted || m.status == StatusError) && m.finalResult != nil {
+		return m.finalResult.Search
+	}
+	return m.lastProgress.Search
+}
+
+// searchMetricsLines describes the progress of a capacity search and its
+// most recent steps.
+func (m Model) searchMetricsLines(search *metrics.SearchResult) []string {
+	var lines []string
+	if search.CurrentRate > 0 {
+		lines = append(lines, fmt.Sprintf("%s %s", metricKeyStyle.Render("Search Step:"),
+			metricValStyle.Render(fmt.Sprintf("%d at %.1f req/s", len(search.Steps)+1, search.CurrentRate))))
+	}
+	sustainable := "none yet"
+	if search.SustainableRate > 0 {
+		sustainable = fmt.Sprintf("%.1f req/s (achieved %.1f req/s)", search.SustainableRate, search.SustainableThroughput)
+	}
+	lines = append(lines, fmt.Sprintf("%s %s", metricKeyStyle.Render("Sustainable Rate:"), metricValStyle.Render(sustainable)))
+
+	const maxSteps = 6
+	steps := search.Steps
+	if len(steps) > maxSteps {
+		steps = steps[len(steps)-maxSteps:]
+	}
+	for _, step := range steps {
+		line := fmt.Sprintf("  %8.1f req/s -> %8.1f req/s, p99 %s", step.Rate, step.Throughput, step.LatencyP99.Round(time.Millisecond))
+		if step.Passed {
+			lines = append(lines, successStyle.Render(line+"  pass"))
+		} else {
+			lines = append(lines, errorStyle.Render(line+"  fail: "+strings.Join(step.Violations, "; ")))
+		}
+	}
+	return lines
+}
+
+// renderSearchCurve plots the p99 latency of every search step against its
+// throughput, with the p99 SLO as a dashed line when one is set.
+func renderSearchCurve(search *metrics.SearchResult, sloP99 time.Duration, width, height int) string {
+	if len(search.Steps) == 0 {
+		return "No completed steps yet."
+	}
+	const labelWidth = 9
+	plotWidth := width - labelWidth - 1
+	if plotWidth < 10 {
+		plotWidth = 10
+	}
+	if height < 3 {
+		height = 3
+	}
+
+	var maxX float64
+	maxY := sloP99
+	for _, step := range search.Steps {
+		maxX = math.Max(maxX, math.Max(step.Throughput, step.Rate))
+		if step.LatencyP99 > maxY {
+			maxY = step.LatencyP99
+		}
+	}
+	maxX *= 1.05
+	maxY = maxY * 11 / 10
+	if sloP99 > 0 && maxY > 4*sloP99 {
+		// Keep the SLO readable; steps far past it are drawn on the top row.
+		maxY = 4 * sloP99
+	}
+	if maxX <= 0 {
+		maxX = 1
+	}
+	if maxY <= 0 {
+		maxY = time.Millisecond
+	}
+	row := func(d time.Duration) int {
+		r := height - 1 - int(math.Round(float64(d)/float64(maxY)*float64(height-1)))
+		return max(0, min(height-1, r))
+	}
+
+	grid := make([][]string, height)
+	for r := range grid {
+		grid[r] = make([]string, plotWidth)
+		for c := range grid[r] {
+			grid[r][c] = " "
+		}
+	}
+	if sloP99 > 0 {
+		r := row(sloP99)
+		for c := 0; c < plotWidth; c += 2 {
+			grid[r][c] = placeholderStyle.Render("╌")
+		}
+	}
+	for _, step := range search.Steps {
+		c := int(step.Throughput / maxX * float64(plotWidth-1))
+		c = max(0, min(plotWidth-1, c))
+		if step.Passed {
+			grid[row(step.LatencyP99)][c] = successStyle.Render("●")
+		} else {
+			grid[row(step.LatencyP99)][c] = errorStyle.Render("✕")
+		}
+	}
+
+	var sb strings.Builder
+	for r, cells := range grid {
+		label := ""
+		switch {
+		case sloP99 > 0 && r == row(sloP99):
+			label = fmt.Sprintf("%dms", sloP99.Milliseconds())
+		case r == 0 || r == height-1:
+			label = fmt.Sprintf("%dms", (maxY * time.Duration(height-1-r) / time.Duration(height-1)).Milliseconds())
+		}
+		sb.WriteString(fmt.Sprintf("%*s │%s\n", labelWidth-2, label, strings.Join(cells, "")))
+	}
+	sb.WriteString(strings.Repeat(" ", labelWidth-1) + "└" + strings.Repeat("─", plotWidth) + "\n")
+	maxLabel := fmt.Sprintf("%.0f req/s", maxX)
+	sb.WriteString(fmt.Sprintf("%*s0%*s", labelWidth, "", plotWidth-1, maxLabel))
+	return sb.String()
+}
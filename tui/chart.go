@@ -0,0 +1,175 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Th4phat/go-wrk/metrics"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// vizMode selects what the visualization panel shows; the chart key cycles
+// through the modes in order.
+type vizMode int
+
+const (
+	vizHistogram vizMode = iota
+	vizTimeSeries
+	vizHeatmap
+	vizModeCount
+)
+
+func (v vizMode) next() vizMode {
+	return (v + 1) % vizModeCount
+}
+
+// chartLine is one line of a line chart.
+type chartLine struct {
+	values []float64
+	style  lipgloss.Style
+}
+
+var (
+	chartP50Style   = lipgloss.NewStyle().Foreground(lipgloss.Color("51"))
+	chartP99Style   = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	chartRPSStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("82"))
+	chartErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+)
+
+// brailleDots are the bits of the dots of a braille cell, indexed by
+// [row][column] within the cell's 4x2 grid.
+var brailleDots = [4][2]rune{{0x01, 0x08}, {0x02, 0x10}, {0x04, 0x20}, {0x40, 0x80}}
+
+// renderLineChart plots lines with braille dots, two points per column and
+// four per row. The y-axis runs from zero to a rounded maximum of the data,
+// labelled with yLabel, and the x-axis spans the whole series.
+func renderLineChart(title string, lines []chartLine, span time.Duration, yLabel func(float64) string, width, height int) string {
+	const labelWidth = 8
+	plotWidth := max(width-labelWidth-1, 4)
+	height = max(height, 2)
+	dotsX, dotsY := plotWidth*2, height*4
+
+	var top float64
+	points := 0
+	for _, l := range lines {
+		for _, v := range l.values {
+			top = math.Max(top, v)
+		}
+		points = max(points, len(l.values))
+	}
+	top = metrics.NiceCeil(top)
+
+	cells := make([][]rune, height)
+	owner := make([][]int, height)
+	for r := range cells {
+		cells[r] = make([]rune, plotWidth)
+		owner[r] = make([]int, plotWidth)
+		for c := range owner[r] {
+			owner[r][c] = -1
+		}
+	}
+	set := func(x, y, line int) {
+		r, c := y/4, x/2
+		cells[r][c] |= brailleDots[y%4][x%2]
+		owner[r][c] = line
+	}
+	toX := func(i int) int {
+		if points < 2 {
+			return 0
+		}
+		return i * (dotsX - 1) / (points - 1)
+	}
+	toY := func(v float64) int {
+		y := dotsY - 1 - int(math.Round(v/top*float64(dotsY-1)))
+		return max(0, min(dotsY-1, y))
+	}
+	for li, l := range lines {
+		for i, v := range l.values {
+			x, y := toX(i), toY(v)
+			set(x, y, li)
+			if i == 0 {
+				continue
+			}
+			// Join the previous point: step across, then fill vertically.
+			px, py := toX(i-1), toY(l.values[i-1])
+			for sx := px + 1; sx < x; sx++ {
+				set(sx, py+(y-py)*(sx-px)/(x-px), li)
+			}
+			for sy := min(py, y) + 1; sy < max(py, y); sy++ {
+				set(x, sy, li)
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(title + "\n")
+	for r := range cells {
+		label := ""
+		switch r {
+		case 0:
+			label = yLabel(top)
+		case height - 1:
+			label = yLabel(0)
+		case height / 2:
+			if height > 4 {
+				label = yLabel(top / 2)
+			}
+		}
+		var row strings.Builder
+		for c, dots := range cells[r] {
+			if owner[r][c] < 0 {
+				row.WriteRune(' ')
+				continue
+			}
+			row.WriteString(lines[owner[r][c]].style.Render(string(0x2800 + dots)))
+		}
+		sb.WriteString(fmt.Sprintf("%*s │%s\n", labelWidth-1, label, row.String()))
+	}
+	sb.WriteString(strings.Repeat(" ", labelWidth) + "└" + strings.Repeat("─", plotWidth) + "\n")
+	end := span.Round(time.Second).String()
+	sb.WriteString(fmt.Sprintf("%*s0s%*s", labelWidth+1, "", max(plotWidth-2, len(end)), end))
+	return sb.String()
+}
+
+// renderTimeSeries draws throughput, error rate and p50/p99 latency of a
+// per-second series as three charts side by side.
+func renderTimeSeries(series []metrics.SeriesPoint, width, height int) string {
+	if len(series) < 2 {
+		return "Collecting per-second data..."
+	}
+	n := len(series)
+	rps := make([]float64, n)
+	errRate := make([]float64, n)
+	p50 := make([]float64, n)
+	p99 := make([]float64, n)
+	for i, p := range series {
+		rps[i] = p.Throughput
+		errRate[i] = p.ErrorRate()
+		p50[i] = float64(p.LatencyP50) / float64(time.Millisecond)
+		p99[i] = float64(p.LatencyP99) / float64(time.Millisecond)
+	}
+	last := series[n-1]
+	span := last.Offset + last.Duration
+
+	chartWidth := (width - 2) / 3
+	count := func(v float64) string { return fmt.Sprintf("%.0f", v) }
+	if top := metrics.NiceCeil(slices.Max(rps)); top >= 10000 {
+		count = func(v float64) string { return fmt.Sprintf("%.0fk", v/1000) }
+	}
+	percent := func(v float64) string { return fmt.Sprintf("%.3g%%", v) }
+	millis := func(v float64) string { return fmt.Sprintf("%.3gms", v) }
+
+	charts := []string{
+		renderLineChart(chartRPSStyle.Render("Throughput (req/s)"), []chartLine{{rps, chartRPSStyle}}, span, count, chartWidth, height),
+		renderLineChart(chartErrorStyle.Render("Error Rate"), []chartLine{{errRate, chartErrorStyle}}, span, percent, chartWidth, height),
+		renderLineChart(fmt.Sprintf("Latency %s %s", chartP50Style.Render("p50"), chartP99Style.Render("p99")),
+			[]chartLine{{p50, chartP50Style}, {p99, chartP99Style}}, span, millis, chartWidth, height),
+	}
+	for i := range charts[:2] {
+		charts[i] = lipgloss.NewStyle().Width(chartWidth + 1).Render(charts[i])
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, charts...)
+}
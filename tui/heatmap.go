@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Th4phat/go-wrk/metrics"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// heatRamp are the colors of the heatmap cells, from the fewest to the most
+// requests.
+var heatRamp = []lipgloss.Color{"17", "18", "19", "55", "91", "127", "163", "199", "205", "209", "214", "220", "226", "229", "231"}
+
+// renderHeatmap draws the per-second latency histograms of a series: time on
+// the x-axis, log-scaled latency buckets on the y-axis and the number of
+// requests as the color of each cell. Adjacent seconds or buckets are merged
+// when they do not fit.
+func renderHeatmap(series []metrics.SeriesPoint, width, height int) string {
+	lo, hi, ok := metrics.HistogramRange(series)
+	if !ok {
+		return "Collecting per-second data..."
+	}
+	const labelWidth = 8
+	plotWidth := max(width-labelWidth-1, 4)
+	height = max(height, 2)
+
+	perRow := (hi - lo + height) / height
+	rows := (hi-lo)/perRow + 1
+	n := len(series)
+	counts := make([][]int, rows)
+	top := 0
+	for r := range counts {
+		counts[r] = make([]int, plotWidth)
+		for c := range counts[r] {
+			from := c * n / plotWidth
+			to := max(from+1, (c+1)*n/plotWidth)
+			for _, p := range series[from:to] {
+				// Row 0 is the top of the plot, holding the slowest buckets.
+				for b := hi - (r+1)*perRow + 1; b <= hi-r*perRow; b++ {
+					if b >= lo && b < len(p.Histogram) {
+						counts[r][c] += p.Histogram[b]
+					}
+				}
+			}
+			top = max(top, counts[r][c])
+		}
+	}
+	level := func(count int) int {
+		return int(math.Log1p(float64(count)) / math.Log1p(float64(top)) * float64(len(heatRamp)-1))
+	}
+
+	var sb strings.Builder
+	for r, row := range counts {
+		label := ""
+		if r == 0 || r == rows-1 || (rows > 4 && r == rows/2) {
+			lower, _ := metrics.LatencyBucketBounds(max(lo, hi-(r+1)*perRow+1))
+			label = metrics.FormatLatency(lower)
+		}
+		var cells strings.Builder
+		for _, count := range row {
+			if count == 0 {
+				cells.WriteRune(' ')
+				continue
+			}
+			cells.WriteString(lipgloss.NewStyle().Foreground(heatRamp[level(count)]).Render("█"))
+		}
+		sb.WriteString(fmt.Sprintf("%*s │%s\n", labelWidth-1, label, cells.String()))
+	}
+	sb.WriteString(strings.Repeat(" ", labelWidth) + "└" + strings.Repeat("─", plotWidth) + "\n")
+	last := series[n-1]
+	end := (last.Offset + last.Duration).Round(time.Second).String()
+	sb.WriteString(fmt.Sprintf("%*s0s%*s\n", labelWidth+1, "", max(plotWidth-2, len(end)), end))
+
+	var legend strings.Builder
+	for _, color := range heatRamp {
+		legend.WriteString(lipgloss.NewStyle().Foreground(color).Render("█"))
+	}
+	sb.WriteString(fmt.Sprintf("%*s1 %s %d requests per cell", labelWidth+1, "", legend.String(), top))
+	return sb.String()
+}
@@ -3,16 +3,38 @@ package tui
 import "github.com/charmbracelet/bubbles/key"
 
 type keyMap struct {
-	Up      key.Binding
-	Down    key.Binding
-	Help    key.Binding
-	Quit    key.Binding
-	Start   key.Binding
-	Stop    key.Binding
-	Refresh key.Binding
-	Enter   key.Binding
-	Back    key.Binding
-	Save    key.Binding
+	Up       key.Binding
+	Down     key.Binding
+	Help     key.Binding
+	Quit     key.Binding
+	Start    key.Binding
+	Stop     key.Binding
+	Refresh  key.Binding
+	Enter    key.Binding
+	Back     key.Binding
+	Save     key.Binding
+	Import   key.Binding
+	Export   key.Binding
+	CopyCurl key.Binding
+	CopyRaw  key.Binding
+	Env      key.Binding
+	Search   key.Binding
+	Chart    key.Binding
+
+	NextField  key.Binding
+	PrevField  key.Binding
+	FormatBody key.Binding
+	MinifyBody key.Binding
+	LoadBody   key.Binding
+	BodyType   key.Binding
+
+	NewCollection key.Binding
+	Rename        key.Binding
+	Duplicate     key.Binding
+	Move          key.Binding
+	Delete        key.Binding
+	Confirm       key.Binding
+	Cancel        key.Binding
 }
 
 func (k keyMap) ShortHelp() []key.Binding {
@@ -24,8 +46,11 @@ func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down},
 		{k.Enter, k.Back},
-		{k.Start, k.Save},
-		{k.Refresh},
+		{k.Start, k.Save, k.Import, k.Export},
+		{k.CopyCurl, k.CopyRaw, k.Env, k.Search},
+		{k.NextField, k.PrevField, k.FormatBody, k.MinifyBody, k.LoadBody, k.BodyType},
+		{k.NewCollection, k.Rename, k.Duplicate, k.Move, k.Delete},
+		{k.Chart, k.Refresh},
 		{k.Help, k.Quit},
 	}
 }
@@ -69,4 +94,84 @@ var keys = keyMap{
 		key.WithKeys("ctrl+s"),
 		key.WithHelp("ctrl+s", "save test"),
 	),
+	Import: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "import curl"),
+	),
+	Export: key.NewBinding(
+		key.WithKeys("ctrl+e"),
+		key.WithHelp("ctrl+e", "export as curl/raw"),
+	),
+	CopyCurl: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "copy curl"),
+	),
+	CopyRaw: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "copy raw request"),
+	),
+	Env: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "switch environment"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "search tests"),
+	),
+	Chart: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "cycle chart"),
+	),
+	NextField: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "next field"),
+	),
+	PrevField: key.NewBinding(
+		key.WithKeys("shift+tab"),
+		key.WithHelp("shift+tab", "previous field"),
+	),
+	FormatBody: key.NewBinding(
+		key.WithKeys("ctrl+f"),
+		key.WithHelp("ctrl+f", "format JSON payload"),
+	),
+	MinifyBody: key.NewBinding(
+		key.WithKeys("ctrl+t"),
+		key.WithHelp("ctrl+t", "minify JSON payload"),
+	),
+	LoadBody: key.NewBinding(
+		key.WithKeys("ctrl+o"),
+		key.WithHelp("ctrl+o", "load payload file"),
+	),
+	BodyType: key.NewBinding(
+		key.WithKeys("ctrl+b"),
+		key.WithHelp("ctrl+b", "cycle body type"),
+	),
+	NewCollection: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "new collection"),
+	),
+	Rename: key.NewBinding(
+		key.WithKeys("R"),
+		key.WithHelp("R", "rename"),
+	),
+	Duplicate: key.NewBinding(
+		key.WithKeys("ctrl+d"),
+		key.WithHelp("ctrl+d", "duplicate test"),
+	),
+	Move: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "move test"),
+	),
+	Delete: key.NewBinding(
+		key.WithKeys("d", "delete"),
+		key.WithHelp("d/del", "delete"),
+	),
+	Confirm: key.NewBinding(
+		key.WithKeys("y", "Y"),
+		key.WithHelp("y", "confirm"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("n", "N", "esc"),
+		key.WithHelp("n/esc", "cancel"),
+	),
 }
@@ -0,0 +1,316 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Th4phat/go-wrk/config"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type manageAction int
+
+const (
+	actionNewCollection manageAction = iota
+	actionRenameCollection
+	actionDeleteCollection
+	actionRenameTest
+	actionDuplicateTest
+	actionMoveTest
+	actionDeleteTest
+	actionOverwriteTest
+)
+
+// manageOp is a collection or test operation waiting for a name or for
+// confirmation.
+type manageOp struct {
+	action       manageAction
+	collection   string
+	test         string
+	target       string                  // Entered name, or collection to move to
+	cfg          *config.BenchmarkConfig // Config being saved, when overwriting
+	returnStatus TUIStatus
+}
+
+// setCollections replaces the loaded collections and the environment names
+// they define.
+func (m *Model) setCollections(collections []config.TestCollection) {
+	m.testCollections = collections
+	m.environments = nil
+	seen := make(map[string]bool)
+	for _, collection := range collections {
+		for _, name := range collection.EnvironmentNames() {
+			if !seen[name] {
+				seen[name] = true
+				m.environments = append(m.environments, name)
+			}
+		}
+	}
+	sort.Strings(m.environments)
+}
+
+// reloadCollections reloads the collections from disk, selecting the named
+// collection and test when they still exist.
+func (m *Model) reloadCollections(selectCollection, selectTest string) {
+	activeName := ""
+	if m.activeCollection >= 0 && m.activeCollection < len(m.testCollections) {
+		activeName = m.testCollections[m.activeCollection].Name
+	}
+
+	collections, report, err := config.LoadTestCollections(config.GetConfigDir())
+	if err != nil {
+		m.addLog(fmt.Sprintf("Reload failed: %v", err))
+		return
+	}
+	m.setCollections(collections)
+	if report != nil && config.HasErrors(report.Problems) {
+		m.addLog("Some test files have errors and were skipped. Run 'go-wrk validate' for details.")
+	}
+
+	m.activeCollection = -1
+	m.selectedCollection = min(m.selectedCollection, len(collections))
+	for i, collection := range collections {
+		if collection.Name == activeName {
+			m.activeCollection = i
+		}
+		if collection.Name != selectCollection {
+			continue
+		}
+		m.selectedCollection = i
+		m.selectedTest = 0
+		for j, test := range collection.Tests {
+			if test.Name == selectTest {
+				m.selectedTest = j
+			}
+		}
+	}
+	if m.selectedCollection < len(collections) {
+		m.selectedTest = min(m.selectedTest, max(len(collections[m.selectedCollection].Tests)-1, 0))
+	}
+}
+
+// startNameEntry prompts for the name an operation needs.
+func (m *Model) startNameEntry(op manageOp, prompt, initial string) tea.Cmd {
+	op.returnStatus = m.status
+	m.pendingOp = op
+	m.manageError = ""
+	m.manageInput.Prompt = prompt
+	m.manageInput.SetValue(initial)
+	m.manageInput.CursorEnd()
+	m.status = StatusEnteringName
+	return textinput.Blink
+}
+
+// startConfirm asks for confirmation before a destructive operation.
+func (m *Model) startConfirm(op manageOp) {
+	op.returnStatus = m.status
+	m.pendingOp = op
+	m.manageError = ""
+	m.status = StatusConfirming
+}
+
+// handleCollectionManageKeys handles the management keys of the collections
+// view. It reports whether the key was one of them.
+func (m *Model) handleCollectionManageKeys(msg tea.KeyMsg) (bool, tea.Cmd) {
+	onCollection := m.selectedCollection >= 0 && m.selectedCollection < len(m.testCollections)
+	switch {
+	case key.Matches(msg, m.keys.NewCollection):
+		return true, m.startNameEntry(manageOp{action: actionNewCollection}, "New collection: ", "")
+	case key.Matches(msg, m.keys.Rename) && onCollection:
+		name := m.testCollections[m.selectedCollection].Name
+		return true, m.startNameEntry(manageOp{action: actionRenameCollection, collection: name}, "Rename collection to: ", name)
+	case key.Matches(msg, m.keys.Delete) && onCollection:
+		m.startConfirm(manageOp{action: actionDeleteCollection, collection: m.testCollections[m.selectedCollection].Name})
+		return true, nil
+	}
+	return false, nil
+}
+
+// handleTestManageKeys handles the management keys of the tests view for the
+// selected test. It reports whether the key was one of them.
+func (m *Model) handleTestManageKeys(msg tea.KeyMsg, collection config.TestCollection) (bool, tea.Cmd) {
+	if m.selectedTest < 0 || m.selectedTest >= len(collection.Tests) {
+		return false, nil
+	}
+	op := manageOp{collection: collection.Name, test: collection.Tests[m.selectedTest].Name}
+	switch {
+	case key.Matches(msg, m.keys.Rename):
+		op.action = actionRenameTest
+		return true, m.startNameEntry(op, "Rename test to: ", op.test)
+	case key.Matches(msg, m.keys.Duplicate):
+		op.action = actionDuplicateTest
+		return true, m.startNameEntry(op, "Duplicate as: ", op.test+"_copy")
+	case key.Matches(msg, m.keys.Move):
+		op.action = actionMoveTest
+		return true, m.startNameEntry(op, "Move to collection: ", "")
+	case key.Matches(msg, m.keys.Delete):
+		op.action = actionDeleteTest
+		m.startConfirm(op)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (m *Model) handleEnteringNameKeys(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.keys.Enter):
+		name := strings.TrimSpace(m.manageInput.Value())
+		if name == "" {
+			m.manageError = "Name cannot be empty."
+			return textinput.Blink
+		}
+		m.pendingOp.target = name
+		if err := m.runPendingOp(); err != nil {
+			m.manageError = err.Error()
+			m.addLog(fmt.Sprintf("Operation failed: %v", err))
+			return textinput.Blink
+		}
+	case key.Matches(msg, m.keys.Back):
+		m.status = m.pendingOp.returnStatus
+		m.addLog("Cancelled.")
+	}
+	return nil
+}
+
+func (m *Model) handleConfirmingKeys(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.keys.Confirm):
+		if err := m.runPendingOp(); err != nil {
+			m.manageError = err.Error()
+			m.addLog(fmt.Sprintf("Operation failed: %v", err))
+		}
+	case key.Matches(msg, m.keys.Cancel):
+		m.status = m.pendingOp.returnStatus
+		m.addLog("Cancelled.")
+	}
+	return nil
+}
+
+// runPendingOp performs the pending operation, reloads the collections and
+// returns to the view it was started from.
+func (m *Model) runPendingOp() error {
+	op := m.pendingOp
+	dir := config.GetConfigDir()
+	selectCollection, selectTest := op.collection, op.test
+	nextStatus := op.returnStatus
+	var err error
+	var done string
+
+	switch op.action {
+	case actionNewCollection:
+		err = config.CreateCollection(dir, op.target)
+		selectCollection = config.SanitizeFilename(op.target)
+		done = fmt.Sprintf("Created collection '%s'.", selectCollection)
+	case actionRenameCollection:
+		err = config.RenameCollection(dir, op.collection, op.target)
+		selectCollection = config.SanitizeFilename(op.target)
+		done = fmt.Sprintf("Renamed collection '%s' to '%s'.", op.collection, selectCollection)
+	case actionDeleteCollection:
+		err = config.DeleteCollection(dir, op.collection)
+		done = fmt.Sprintf("Deleted collection '%s'.", op.collection)
+	case actionRenameTest:
+		err = config.RenameTest(dir, op.collection, op.test, op.target)
+		selectTest = config.SanitizeFilename(op.target)
+		done = fmt.Sprintf("Renamed test '%s' to '%s'.", op.test, selectTest)
+	case actionDuplicateTest:
+		err = config.DuplicateTest(dir, op.collection, op.test, op.target)
+		selectTest = config.SanitizeFilename(op.target)
+		done = fmt.Sprintf("Duplicated test '%s' as '%s'.", op.test, selectTest)
+	case actionMoveTest:
+		err = config.MoveTest(dir, op.collection, op.test, op.target, op.test)
+		selectCollection, _ = config.SavedNames(dir, op.target, op.test)
+		done = fmt.Sprintf("Moved test '%s' to collection '%s'.", op.test, selectCollection)
+	case actionDeleteTest:
+		err = config.DeleteTest(dir, op.collection, op.test)
+		selectTest = ""
+		done = fmt.Sprintf("Deleted test '%s' from '%s'.", op.test, op.collection)
+	case actionOverwriteTest:
+		err = config.ReplaceTestInCollection(dir, op.collection, op.test, *op.cfg)
+		nextStatus = StatusIdle
+		m.currentConfigToSave = nil
+		m.saveError = ""
+		done = fmt.Sprintf("Overwrote test '%s' in collection '%s'.", op.test, op.collection)
+	}
+	if err != nil {
+		if errors.Is(err, config.ErrTestExists) || errors.Is(err, config.ErrCollectionExists) {
+			err = fmt.Errorf("%w; choose another name", err)
+		}
+		return err
+	}
+
+	m.addLog(successStyle.Render(done))
+	m.reloadCollections(selectCollection, selectTest)
+	if nextStatus == StatusViewingTests && op.action == actionMoveTest {
+		nextStatus = StatusViewingCollections
+	}
+	if nextStatus == StatusViewingTests && (m.selectedCollection >= len(m.testCollections) || m.testCollections[m.selectedCollection].Name != op.collection) {
+		nextStatus = StatusViewingCollections
+	}
+	m.status = nextStatus
+	return nil
+}
+
+// confirmMessage describes the pending destructive operation.
+func (m Model) confirmMessage() string {
+	op := m.pendingOp
+	switch op.action {
+	case actionDeleteCollection:
+		count := 0
+		for _, collection := range m.testCollections {
+			if collection.Name == op.collection {
+				count = len(collection.Tests)
+			}
+		}
+		return fmt.Sprintf("Delete collection '%s' and its %d test(s)? This cannot be undone.", op.collection, count)
+	case actionDeleteTest:
+		return fmt.Sprintf("Delete test '%s' from collection '%s'? This cannot be undone.", op.test, op.collection)
+	case actionOverwriteTest:
+		return fmt.Sprintf("Test '%s' already exists in collection '%s'. Overwrite it?", op.test, op.collection)
+	}
+	return "Proceed?"
+}
+
+func (m Model) viewEnteringName() string {
+	b := strings.Builder{}
+	op := m.pendingOp
+	switch op.action {
+	case actionNewCollection:
+		b.WriteString("New Collection\n\n")
+	case actionRenameCollection:
+		b.WriteString(fmt.Sprintf("Rename Collection '%s'\n\n", op.collection))
+	default:
+		b.WriteString(fmt.Sprintf("Test '%s' in '%s'\n\n", op.test, op.collection))
+	}
+	b.WriteString(m.manageInput.View() + "\n\n")
+	if op.action == actionMoveTest {
+		names := make([]string, 0, len(m.testCollections))
+		for _, collection := range m.testCollections {
+			if collection.Name != op.collection {
+				names = append(names, collection.Name)
+			}
+		}
+		if len(names) > 0 {
+			b.WriteString(fmt.Sprintf("Collections: %s (a new name creates one)\n", strings.Join(names, ", ")))
+		}
+	}
+	if m.manageError != "" {
+		b.WriteString(errorStyle.Render(m.manageError) + "\n")
+	}
+	b.WriteString("Press Enter to confirm, Esc to cancel.")
+	return panelStyle.Width(m.windowWidth - 4).Render(b.String())
+}
+
+func (m Model) viewConfirming() string {
+	b := strings.Builder{}
+	b.WriteString(m.confirmMessage() + "\n\n")
+	if m.manageError != "" {
+		b.WriteString(errorStyle.Render(m.manageError) + "\n")
+	}
+	b.WriteString("Press y to confirm, n or Esc to cancel.")
+	return panelStyle.Width(m.windowWidth - 4).Render(b.String())
+}
@@ -1,10 +1,10 @@
 package tui
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Th4phat/go-wrk/benchmark"
@@ -12,6 +12,7 @@ import (
 	"github.com/Th4phat/go-wrk/metrics"
 
 	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -30,6 +31,13 @@ const (
 
 	StatusSavingEnterCollectionName
 	StatusSavingEnterTestName
+	StatusImportingCurl
+	StatusViewingExport
+	StatusEnteringName
+	StatusConfirming
+	StatusSearching
+	StatusLoadingBody
+	StatusEnteringMethod
 )
 
 const maxLogMessages = 100
@@ -40,21 +48,59 @@ type Model struct {
 
 	testCollections []config.TestCollection
 
+	// environments lists every environment name defined by a collection;
+	// environment is the selected one ("" for none). activeCollection is the
+	// index of the collection the loaded test came from, or -1.
+	environments     []string
+	environment      string
+	activeCollection int
+
 	targetURLInput   textinput.Model
 	threadsInput     textinput.Model
 	connectionsInput textinput.Model
 	durationInput    textinput.Model
-	requestPayload   textinput.Model
+	requestPayload   textarea.Model
 	focusedInput     int
 	configError      string
 
+	// baseConfig holds the loaded test's settings that have no input field
+	// (headers, TLS options, body type and file), so they survive editing,
+	// running and saving.
+	baseConfig config.BenchmarkConfig
+
+	bodyFileInput textinput.Model
+	bodyFileError string
+
 	saveCollectionNameInput textinput.Model
 	saveTestNameInput       textinput.Model
 	currentConfigToSave     *config.BenchmarkConfig
 	saveError               string
 
+	curlImportInput textinput.Model
+	importError     string
+
+	exportName         string
+	exportCurl         string
+	exportRaw          string
+	exportNotice       string
+	exportReturnStatus TUIStatus
+
+	searchInput        textinput.Model
+	searchResults      []searchResult
+	selectedResult     int
+	searchReturnStatus TUIStatus
+
+	pendingOp   manageOp
+	manageInput textinput.Model
+	manageError string
+
+	// httpMethods lists the selectable methods, including custom ones entered
+	// or loaded this session. selectedMethod == len(httpMethods) is the custom
+	// method entry of the method selection view.
 	httpMethods    []string
 	selectedMethod int
+	methodInput    textinput.Model
+	methodError    string
 
 	benchmarkEngine *benchmark.Engine
 	progressChan    <-chan metrics.ProgressUpdate
@@ -66,6 +112,7 @@ type Model struct {
 
 	lastProgress metrics.ProgressUpdate
 	finalResult  *metrics.BenchmarkResult
+	vizMode      vizMode
 
 	logMessages  []string
 	windowWidth  int
@@ -77,20 +124,22 @@ type Model struct {
 	selectedTest       int
 }
 
-func NewModel(testCollections []config.TestCollection, logFile *os.File) Model {
+func NewModel(testCollections []config.TestCollection, loadReport *config.LoadReport, environment string, logFile *os.File) Model {
 	m := Model{
 		keys:               keys,
 		help:               help.New(),
-		testCollections:    testCollections,
 		status:             StatusViewingCollections,
 		logMessages:        []string{"Welcome! Select a collection or choose [ New Benchmark ]."},
 		benchmarkEngine:    benchmark.NewEngine(),
 		selectedCollection: 0,
 		selectedTest:       0,
-		httpMethods:        []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
+		httpMethods:        []string{"GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS", "TRACE", "PROPFIND", "REPORT"},
 		selectedMethod:     0,
 		logFile:            logFile,
+		environment:        environment,
+		activeCollection:   -1,
 	}
+	m.setCollections(testCollections)
 
 	m.targetURLInput = textinput.New()
 	m.targetURLInput.Placeholder = "http://example.com/api"
@@ -161,14 +210,30 @@ func NewModel(testCollections []config.TestCollection, logFile *os.File) Model {
 		return err
 	}
 
-	m.requestPayload = textinput.New()
-	m.requestPayload.Placeholder = "Enter request payload (JSON for POST/PUT/PATCH)"
-	m.requestPayload.Prompt = "Payload: "
-	m.requestPayload.PromptStyle = inputDefaultStyle
-	m.requestPayload.TextStyle = inputDefaultStyle
-	m.requestPayload.PlaceholderStyle = placeholderStyle
+	m.requestPayload = textarea.New()
+	m.requestPayload.Placeholder = "Enter request payload, or press Ctrl+O to load it from a file"
+	m.requestPayload.FocusedStyle.Placeholder = placeholderStyle
+	m.requestPayload.BlurredStyle.Placeholder = placeholderStyle
 	m.requestPayload.CharLimit = 0
-	m.requestPayload.Width = 50
+	m.requestPayload.MaxHeight = 0
+	m.requestPayload.SetWidth(60)
+	m.requestPayload.SetHeight(8)
+
+	m.methodInput = textinput.New()
+	m.methodInput.Placeholder = "PURGE"
+	m.methodInput.Prompt = "Method: "
+	m.methodInput.PromptStyle = focusedStyle
+	m.methodInput.TextStyle = focusedStyle
+	m.methodInput.CharLimit = 32
+	m.methodInput.Width = 32
+
+	m.bodyFileInput = textinput.New()
+	m.bodyFileInput.Placeholder = "./payload.json"
+	m.bodyFileInput.Prompt = "File: "
+	m.bodyFileInput.PromptStyle = focusedStyle
+	m.bodyFileInput.TextStyle = focusedStyle
+	m.bodyFileInput.CharLimit = 0
+	m.bodyFileInput.Width = 60
 
 	m.saveCollectionNameInput = textinput.New()
 	m.saveCollectionNameInput.Placeholder = "my_api_tests"
@@ -186,10 +251,53 @@ func NewModel(testCollections []config.TestCollection, logFile *os.File) Model {
 	m.saveTestNameInput.CharLimit = 50
 	m.saveTestNameInput.Width = 40
 
+	m.curlImportInput = textinput.New()
+	m.curlImportInput.Placeholder = "curl 'https://example.com/api' -H 'Accept: application/json'"
+	m.curlImportInput.Prompt = "curl command: "
+	m.curlImportInput.PromptStyle = focusedStyle
+	m.curlImportInput.TextStyle = focusedStyle
+	m.curlImportInput.CharLimit = 0
+	m.curlImportInput.Width = 60
+
+	m.searchInput = textinput.New()
+	m.searchInput.Placeholder = "collection, test, URL or method"
+	m.searchInput.Prompt = "/"
+	m.searchInput.PromptStyle = focusedStyle
+	m.searchInput.TextStyle = focusedStyle
+	m.searchInput.CharLimit = 100
+	m.searchInput.Width = 50
+
+	m.manageInput = textinput.New()
+	m.manageInput.PromptStyle = focusedStyle
+	m.manageInput.TextStyle = focusedStyle
+	m.manageInput.CharLimit = 50
+	m.manageInput.Width = 40
+
 	m.focusedInput = -1
+	m.logLoadReport(loadReport)
 	return m
 }
 
+// logLoadReport lists the problems found while loading test files.
+func (m *Model) logLoadReport(report *config.LoadReport) {
+	if report == nil {
+		return
+	}
+	skipped := make(map[string]bool)
+	for _, p := range report.Problems {
+		m.addLog(p.String())
+		if !p.Warning {
+			skipped[p.File] = true
+		}
+	}
+	if len(skipped) > 0 {
+		m.addLog(fmt.Sprintf("Skipped %d test file(s) with errors. Run 'go-wrk validate' for details.", len(skipped)))
+	}
+	if n := len(report.Migrated); n > 0 {
+		m.addLog(fmt.Sprintf("Loaded %d test file(s) from an older schema version. Run 'go-wrk validate -migrate' to upgrade them.", n))
+	}
+}
+
 func (m Model) Init() tea.Cmd { return nil }
 
 func (m *Model) addLog(message string) {
@@ -216,10 +324,81 @@ func (m *Model) clearConfigInputs() {
 	m.connectionsInput.SetValue("")
 	m.durationInput.SetValue("")
 	m.requestPayload.SetValue("")
+	m.baseConfig = config.BenchmarkConfig{}
+	m.activeCollection = -1
 	m.configError = ""
 	m.focusedInput = -1
 }
 
+// loadConfig fills the configuration inputs from cfg. A method that is not
+// selectable yet is added as a custom method; it returns false if the method
+// is invalid.
+func (m *Model) loadConfig(cfg config.BenchmarkConfig) bool {
+	m.baseConfig = cfg
+	m.activeCollection = -1
+	m.targetURLInput.SetValue(cfg.TargetURL)
+	m.threadsInput.SetValue(countValue(cfg.Threads))
+	m.connectionsInput.SetValue(countValue(cfg.Connections))
+	m.durationInput.SetValue(cfg.Duration)
+	if config.IsFormBody(cfg.BodyType) {
+		m.requestPayload.SetValue(formLines(cfg))
+	} else {
+		m.requestPayload.SetValue(cfg.Payload)
+	}
+
+	m.selectedMethod = 0
+	if cfg.Method == "" {
+		return true
+	}
+	return m.selectMethod(cfg.Method)
+}
+
+// inheritedMethod returns the method the loaded test runs with when it sets
+// none: its collection's default, or GET.
+func (m Model) inheritedMethod() string {
+	if method := m.withDefaults(config.BenchmarkConfig{}).Method; method != "" {
+		return method
+	}
+	return "GET"
+}
+
+// countValue formats a count for its input, leaving an unset count empty.
+func countValue(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// selectMethod selects method, adding it to the selectable methods if needed.
+// It returns false if the method is invalid.
+func (m *Model) selectMethod(method string) bool {
+	for i, existing := range m.httpMethods {
+		if strings.EqualFold(existing, method) {
+			m.selectedMethod = i
+			return true
+		}
+	}
+	if config.CheckMethod(method) != nil {
+		return false
+	}
+	m.httpMethods = append(m.httpMethods, method)
+	m.selectedMethod = len(m.httpMethods) - 1
+	return true
+}
+
+// isTextEntry reports whether key presses are currently going to a text input,
+// in which case printable global shortcuts must not trigger.
+func (m *Model) isTextEntry() bool {
+	switch m.status {
+	case StatusIdle:
+		return m.focusedInput >= 0
+	case StatusSavingEnterCollectionName, StatusSavingEnterTestName, StatusImportingCurl, StatusEnteringName, StatusSearching, StatusLoadingBody, StatusEnteringMethod:
+		return true
+	}
+	return false
+}
+
 func (m *Model) updateInputFocus() {
 	if m.status != StatusIdle &&
 		m.status != StatusSavingEnterCollectionName &&
@@ -228,21 +407,13 @@ func (m *Model) updateInputFocus() {
 		m.threadsInput.Blur()
 		m.connectionsInput.Blur()
 		m.durationInput.Blur()
-		m.requestPayload.Blur()
 	}
 	if m.status == StatusIdle {
-		numInputs := 4
-		currentMethod := ""
-		if m.selectedMethod >= 0 && m.selectedMethod < len(m.httpMethods) {
-			currentMethod = m.httpMethods[m.selectedMethod]
-		}
-		if currentMethod == "POST" || currentMethod == "PUT" || currentMethod == "PATCH" {
-			numInputs = 5
-		}
+		numInputs := numConfigInputs
 
 		inputs := []*textinput.Model{
 			&m.targetURLInput, &m.threadsInput, &m.connectionsInput,
-			&m.durationInput, &m.requestPayload,
+			&m.durationInput,
 		}
 		for i, input := range inputs {
 			shouldFocus := i == m.focusedInput && i < numInputs
@@ -261,7 +432,6 @@ func (m *Model) updateInputFocus() {
 		m.threadsInput.Blur()
 		m.connectionsInput.Blur()
 		m.durationInput.Blur()
-		m.requestPayload.Blur()
 	}
 
 	if m.status == StatusSavingEnterCollectionName {
@@ -275,22 +445,44 @@ func (m *Model) updateInputFocus() {
 		m.saveTestNameInput.Blur()
 	}
 
-	isIdle := m.status == StatusIdle
-	numInputs := 4
-	currentMethod := ""
-	if m.selectedMethod >= 0 && m.selectedMethod < len(m.httpMethods) {
-		currentMethod = m.httpMethods[m.selectedMethod]
+	if m.status == StatusImportingCurl {
+		m.curlImportInput.Focus()
+	} else {
+		m.curlImportInput.Blur()
 	}
-	if currentMethod == "POST" || currentMethod == "PUT" || currentMethod == "PATCH" {
-		numInputs = 5
+
+	if m.status == StatusEnteringName {
+		m.manageInput.Focus()
+	} else {
+		m.manageInput.Blur()
 	}
 
+	if m.status == StatusSearching {
+		m.searchInput.Focus()
+	} else {
+		m.searchInput.Blur()
+	}
+
+	if m.status == StatusLoadingBody {
+		m.bodyFileInput.Focus()
+	} else {
+		m.bodyFileInput.Blur()
+	}
+
+	if m.status == StatusEnteringMethod {
+		m.methodInput.Focus()
+	} else {
+		m.methodInput.Blur()
+	}
+
+	isIdle := m.status == StatusIdle
+	numInputs := numConfigInputs
+
 	inputs := []*textinput.Model{
 		&m.targetURLInput,
 		&m.threadsInput,
 		&m.connectionsInput,
 		&m.durationInput,
-		&m.requestPayload,
 	}
 
 	for i, input := range inputs {
@@ -305,30 +497,34 @@ func (m *Model) updateInputFocus() {
 			input.Blur()
 		}
 	}
+	if isIdle && m.focusedInput == payloadInput {
+		m.requestPayload.Focus()
+	} else {
+		m.requestPayload.Blur()
+	}
 }
 
 func (m *Model) parseConfig() (config.BenchmarkConfig, error) {
-	cfg := config.BenchmarkConfig{}
+	cfg := m.baseConfig
 	var err error
 
 	cfg.TargetURL = m.targetURLInput.Value()
 
-	threadsStr := m.threadsInput.Value()
-	if threadsStr == "" {
-		return cfg, fmt.Errorf("threads cannot be empty")
-	}
-	cfg.Threads, err = strconv.Atoi(threadsStr)
-	if err != nil {
-		return cfg, fmt.Errorf("invalid Threads: %w", err)
+	// An empty field is left unset, for the collection's defaults to fill.
+	cfg.Threads = 0
+	if threadsStr := m.threadsInput.Value(); threadsStr != "" {
+		cfg.Threads, err = strconv.Atoi(threadsStr)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid Threads: %w", err)
+		}
 	}
 
-	connectionsStr := m.connectionsInput.Value()
-	if connectionsStr == "" {
-		return cfg, fmt.Errorf("connections cannot be empty")
-	}
-	cfg.Connections, err = strconv.Atoi(connectionsStr)
-	if err != nil {
-		return cfg, fmt.Errorf("invalid Connections: %w", err)
+	cfg.Connections = 0
+	if connectionsStr := m.connectionsInput.Value(); connectionsStr != "" {
+		cfg.Connections, err = strconv.Atoi(connectionsStr)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid Connections: %w", err)
+		}
 	}
 
 	cfg.Duration = m.durationInput.Value()
@@ -337,25 +533,80 @@ func (m *Model) parseConfig() (config.BenchmarkConfig, error) {
 		return cfg, fmt.Errorf("invalid HTTP method selected")
 	}
 	cfg.Method = m.httpMethods[m.selectedMethod]
-	cfg.Payload = m.requestPayload.Value()
+	if m.baseConfig.Method == "" && strings.EqualFold(cfg.Method, m.inheritedMethod()) {
+		cfg.Method = "" // Still the collection's default
+	}
+	if config.IsFormBody(cfg.BodyType) {
+		cfg.Form, err = parseFormLines(m.requestPayload.Value())
+		if err != nil {
+			return cfg, fmt.Errorf("invalid form payload: %w", err)
+		}
+		cfg.Payload = ""
+	} else {
+		cfg.Payload = m.requestPayload.Value()
+		cfg.Form = nil
+	}
 
-	if err := cfg.Validate(); err != nil {
+	// The config keeps only what the test sets, but must be valid once the
+	// collection's defaults are applied.
+	merged := m.withDefaults(cfg)
+	if err := merged.Validate(); err != nil {
 		return cfg, err
 	}
 
-	if (cfg.Method == "POST" || cfg.Method == "PUT" || cfg.Method == "PATCH") && cfg.Payload != "" {
-		if !isValidJSON(cfg.Payload) {
-			m.addLog("Warning: Payload provided but is not valid JSON.")
-		}
+	if err := m.payloadError(); err != nil {
+		m.addLog(fmt.Sprintf("Warning: %v.", err))
 	}
 	return cfg, nil
 }
 
-func isValidJSON(s string) bool {
-	if s == "" {
-		return true
+// effectiveConfig returns the loaded test's settings with its collection's
+// defaults applied, as they will be run.
+func (m Model) effectiveConfig() config.BenchmarkConfig {
+	return m.withDefaults(m.baseConfig)
+}
+
+// withDefaults applies the defaults of the loaded test's collection to cfg.
+func (m Model) withDefaults(cfg config.BenchmarkConfig) config.BenchmarkConfig {
+	if m.activeCollection >= 0 && m.activeCollection < len(m.testCollections) {
+		return m.testCollections[m.activeCollection].ApplyDefaults(cfg)
+	}
+	return cfg
+}
+
+// resolveConfig applies the defaults of the loaded test's collection to cfg
+// and substitutes its variables, with those of the selected environment
+// taking precedence.
+func (m *Model) resolveConfig(cfg config.BenchmarkConfig) (config.BenchmarkConfig, error) {
+	var err error
+	if m.activeCollection >= 0 && m.activeCollection < len(m.testCollections) {
+		cfg, err = m.testCollections[m.activeCollection].Resolve(cfg, m.environment)
+	} else {
+		cfg, err = config.ExpandVariables(cfg, nil)
+	}
+	if err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// cycleEnvironment selects the next environment, wrapping around to none.
+func (m *Model) cycleEnvironment() {
+	if len(m.environments) == 0 {
+		m.addLog("No environments defined. Add them to a collection's collection.json.")
+		return
+	}
+	next := 0
+	for i, name := range m.environments {
+		if name == m.environment {
+			next = i + 1
+		}
+	}
+	if next < len(m.environments) {
+		m.environment = m.environments[next]
+		m.addLog(fmt.Sprintf("Environment: %s", m.environment))
+	} else {
+		m.environment = ""
+		m.addLog("Environment: none")
 	}
-	var jsObj map[string]interface{}
-	var jsArr []interface{}
-	return json.Unmarshal([]byte(s), &jsObj) == nil || json.Unmarshal([]byte(s), &jsArr) == nil
 }
@@ -0,0 +1,244 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const maxSearchRows = 12
+
+// searchResult is a collection or test matching the search query.
+type searchResult struct {
+	collection int
+	test       int // -1 for the collection itself
+	score      int
+	matched    []int // Rune positions of the match in the "collection / test" label
+}
+
+// Scores of a fuzzy match: every matched rune scores matchScore, plus a bonus
+// when it continues the previous match or starts a word.
+const (
+	matchScore       = 16
+	consecutiveBonus = 24
+	boundaryBonus    = 20
+	gapPenalty       = 1
+)
+
+// fuzzyMatch matches pattern as a case-insensitive subsequence of text. It
+// returns the score and matched rune positions, preferring matches that are
+// contiguous or at word starts.
+func fuzzyMatch(pattern, text string) (int, []int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(text)
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+
+	// Greedy forward scan to find the end of the earliest match, then scan
+	// back from it for the tightest window, as fzf's v1 algorithm does.
+	pi, end := 0, -1
+	for i, r := range t {
+		if unicode.ToLower(r) == p[pi] {
+			pi++
+			if pi == len(p) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+	start := end
+	for pi = len(p) - 1; start >= 0; start-- {
+		if unicode.ToLower(t[start]) == p[pi] {
+			pi--
+			if pi < 0 {
+				break
+			}
+		}
+	}
+
+	var positions []int
+	score, pi, prev := 0, 0, -2
+	for i := start; i <= end && pi < len(p); i++ {
+		if unicode.ToLower(t[i]) != p[pi] {
+			continue
+		}
+		score += matchScore
+		if i == prev+1 {
+			score += consecutiveBonus
+		}
+		if i == 0 || !unicode.IsLetter(t[i-1]) && !unicode.IsDigit(t[i-1]) ||
+			unicode.IsUpper(t[i]) && unicode.IsLower(t[i-1]) {
+			score += boundaryBonus
+		}
+		if prev >= 0 {
+			score -= (i - prev - 1) * gapPenalty
+		}
+		positions = append(positions, i)
+		prev = i
+		pi++
+	}
+	return score, positions, true
+}
+
+func testLabel(collection, test string) string {
+	return collection + " / " + test
+}
+
+// matchTerms scores result against every term, each of which must match the
+// label, URL or method; the label scores highest.
+func matchTerms(result *searchResult, terms []string, label, url, method string) bool {
+	for _, term := range terms {
+		best, found := 0, false
+		if score, positions, ok := fuzzyMatch(term, label); ok {
+			best, found = score*2, true
+			result.matched = append(result.matched, positions...)
+		}
+		if score, _, ok := fuzzyMatch(term, url); ok && score > best {
+			best, found = score, true
+		}
+		if strings.EqualFold(term, method) {
+			best, found = max(best, matchScore*len(term)*2), true
+		}
+		if !found {
+			return false
+		}
+		result.score += best
+	}
+	return true
+}
+
+// updateSearchResults ranks every collection and test against the query.
+// Collections are matched by name, so empty ones can be found too.
+func (m *Model) updateSearchResults() {
+	terms := strings.Fields(m.searchInput.Value())
+	m.searchResults = m.searchResults[:0]
+	for ci, collection := range m.testCollections {
+		result := searchResult{collection: ci, test: -1}
+		if matchTerms(&result, terms, collection.Name, "", "") {
+			m.searchResults = append(m.searchResults, result)
+		}
+		for ti, test := range collection.Tests {
+			cfg := collection.ApplyDefaults(test.Config)
+			result := searchResult{collection: ci, test: ti}
+			if matchTerms(&result, terms, testLabel(collection.Name, test.Name), cfg.TargetURL, cfg.Method) {
+				m.searchResults = append(m.searchResults, result)
+			}
+		}
+	}
+	sort.SliceStable(m.searchResults, func(i, j int) bool {
+		return m.searchResults[i].score > m.searchResults[j].score
+	})
+	m.selectedResult = min(m.selectedResult, max(len(m.searchResults)-1, 0))
+}
+
+func (m *Model) startSearch() tea.Cmd {
+	m.searchReturnStatus = m.status
+	m.status = StatusSearching
+	m.searchInput.SetValue("")
+	m.selectedResult = 0
+	m.updateSearchResults()
+	return textinput.Blink
+}
+
+func (m *Model) handleSearchingKeys(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.keys.Down):
+		if len(m.searchResults) > 0 {
+			m.selectedResult = (m.selectedResult + 1) % len(m.searchResults)
+		}
+	case key.Matches(msg, m.keys.Up):
+		if len(m.searchResults) > 0 {
+			m.selectedResult = (m.selectedResult - 1 + len(m.searchResults)) % len(m.searchResults)
+		}
+	case key.Matches(msg, m.keys.Enter):
+		if len(m.searchResults) == 0 {
+			return nil
+		}
+		result := m.searchResults[m.selectedResult]
+		if result.test < 0 {
+			m.selectedCollection = result.collection
+			m.selectedTest = 0
+			m.status = StatusViewingTests
+			m.addLog(fmt.Sprintf("Viewing tests in collection: %s", m.testCollections[result.collection].Name))
+			return nil
+		}
+		return m.loadTest(result.collection, result.test)
+	case key.Matches(msg, m.keys.Back):
+		m.status = m.searchReturnStatus
+		m.addLog("Search closed.")
+	}
+	return nil
+}
+
+func (m Model) viewSearch() string {
+	b := strings.Builder{}
+	b.WriteString("Search Collections and Tests\n\n")
+	b.WriteString(m.searchInput.View() + "\n\n")
+
+	total := len(m.testCollections)
+	for _, collection := range m.testCollections {
+		total += len(collection.Tests)
+	}
+	if len(m.searchResults) == 0 {
+		b.WriteString(placeholderStyle.Render("No matching collections or tests.") + "\n")
+	}
+
+	// Keep the selected result within the visible window.
+	first := 0
+	if m.selectedResult >= maxSearchRows {
+		first = m.selectedResult - maxSearchRows + 1
+	}
+	last := min(first+maxSearchRows, len(m.searchResults))
+	for i := first; i < last; i++ {
+		result := m.searchResults[i]
+		collection := m.testCollections[result.collection]
+		var line string
+		if result.test < 0 {
+			line = fmt.Sprintf("%s  %s", highlightMatches(collection.Name, result.matched),
+				placeholderStyle.Render(fmt.Sprintf("collection, %d tests", len(collection.Tests))))
+		} else {
+			test := collection.Tests[result.test]
+			cfg := collection.ApplyDefaults(test.Config)
+			label := highlightMatches(testLabel(collection.Name, test.Name), result.matched)
+			line = fmt.Sprintf("%s  %s %s", label, cfg.Method, cfg.TargetURL)
+		}
+		if i == m.selectedResult {
+			b.WriteString(selectedItemStyle.Render("> ") + line + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+
+	b.WriteString(fmt.Sprintf("\n%d of %d collections and tests. Use ↑↓ to navigate, Enter to open, Esc to close.", len(m.searchResults), total))
+	return panelStyle.Width(m.windowWidth - 4).Render(b.String())
+}
+
+// highlightMatches renders the runes of s at the given positions in the match
+// style.
+func highlightMatches(s string, positions []int) string {
+	if len(positions) == 0 {
+		return s
+	}
+	marked := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		marked[p] = true
+	}
+	var b strings.Builder
+	for i, r := range []rune(s) {
+		if marked[i] {
+			b.WriteString(matchStyle.Render(string(r)))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
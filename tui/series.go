@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Th4phat/go-wrk/metrics"
+)
+
+// sparkBlocks are the levels of a sparkline, lowest first.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders values as a single line of block characters scaled
+// between zero and the largest value, keeping the last width values.
+func sparkline(values []float64, width int) string {
+	width = max(width, 10)
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	var top float64
+	for _, v := range values {
+		top = max(top, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if top > 0 {
+			level = int(v / top * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
+
+// seriesLines renders the throughput, p99 latency and errors of a per-second
+// series as labelled sparklines.
+func seriesLines(series []metrics.SeriesPoint, width int) []string {
+	if len(series) < 2 {
+		return nil
+	}
+	throughput := make([]float64, len(series))
+	p99 := make([]float64, len(series))
+	errors := make([]float64, len(series))
+	var peakThroughput float64
+	var peakP99 time.Duration
+	peakErrors := 0
+	for i, p := range series {
+		throughput[i] = p.Throughput
+		p99[i] = float64(p.LatencyP99)
+		errors[i] = float64(p.Errors)
+		peakThroughput = max(peakThroughput, p.Throughput)
+		peakP99 = max(peakP99, p.LatencyP99)
+		peakErrors = max(peakErrors, p.Errors)
+	}
+	line := func(name string, values []float64, peak string) string {
+		return fmt.Sprintf("%s %s %s", metricKeyStyle.Render(name), metricValStyle.Render(sparkline(values, width)), "peak "+peak)
+	}
+	return []string{
+		line("Throughput/s:", throughput, fmt.Sprintf("%.0f req/sec", peakThroughput)),
+		line("P99/s:", p99, peakP99.Round(time.Millisecond).String()),
+		line("Errors/s:", errors, fmt.Sprint(peakErrors)),
+	}
+}
+
+// saveResult writes the final result, with its series, to a timestamped
+// result file in the working directory and returns its path.
+func (m Model) saveResult() (string, error) {
+	if m.finalResult == nil {
+		return "", fmt.Errorf("no result to save")
+	}
+	path := fmt.Sprintf("go-wrk-result-%s.json", time.Now().Format("20060102-150405"))
+	run := metrics.RunFile{
+		Environment: m.environment,
+		Tests:       []metrics.TestResult{{Result: *m.finalResult}},
+	}
+	if m.activeCollection >= 0 && m.activeCollection < len(m.testCollections) {
+		run.Tests[0].Collection = m.testCollections[m.activeCollection].Name
+	}
+	if err := metrics.WriteRunFile(path, run); err != nil {
+		return "", err
+	}
+	return path, nil
+}
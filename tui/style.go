@@ -20,4 +20,5 @@ var (
 	metricValStyle    = lipgloss.NewStyle().Bold(true)
 	histBarStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("51"))
 	selectedItemStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	matchStyle        = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
 )
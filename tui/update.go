@@ -1,15 +1,18 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
-	"strconv"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/Th4phat/go-wrk/benchmark"
 	"github.com/Th4phat/go-wrk/config"
+	"github.com/Th4phat/go-wrk/convert"
 	"github.com/Th4phat/go-wrk/metrics"
 
+	"github.com/aymanbagabas/go-osc52/v2"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -18,6 +21,25 @@ import (
 type progressMsg metrics.ProgressUpdate
 type resultMsg metrics.BenchmarkResult
 type benchmarkCompleteMsg struct{}
+type clipboardMsg struct {
+	what string
+	err  error
+}
+
+// copyToClipboard sets the terminal's clipboard with an OSC52 escape sequence,
+// which also works over SSH.
+func copyToClipboard(what, text string) tea.Cmd {
+	return func() tea.Msg {
+		seq := osc52.New(text)
+		if os.Getenv("TMUX") != "" {
+			seq = seq.Tmux()
+		} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+			seq = seq.Screen()
+		}
+		_, err := seq.WriteTo(os.Stderr)
+		return clipboardMsg{what: what, err: err}
+	}
+}
 
 func listenForProgress(progressChan <-chan metrics.ProgressUpdate) tea.Cmd {
 	return func() tea.Msg {
@@ -45,17 +67,22 @@ func listenForResult(resultChan <-chan metrics.BenchmarkResult) tea.Cmd {
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 	var _ tea.Cmd
+	statusBeforeMsg := m.status
 
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.windowWidth = msg.Width
 		m.windowHeight = msg.Height
 		m.help.Width = msg.Width
+		m.requestPayload.SetWidth(max(msg.Width-10, 20))
 
 	case tea.KeyMsg:
 
+		// While typing, only ctrl+c quits and "?" is ordinary text.
+		typing := m.isTextEntry()
+
 		switch {
-		case key.Matches(msg, m.keys.Quit):
+		case key.Matches(msg, m.keys.Quit) && (!typing || msg.String() == "ctrl+c"):
 			m.quitting = true
 			if m.status == StatusRunning || m.status == StatusStopping {
 				m.addLog("Quit key: Stopping benchmark engine...")
@@ -66,7 +93,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, tea.Quit
 
-		case key.Matches(msg, m.keys.Help):
+		case key.Matches(msg, m.keys.Help) && !typing:
 			m.help.ShowAll = !m.help.ShowAll
 			return m, nil
 
@@ -90,6 +117,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.selectedMethod = 0
 			m.benchmarkEngine = benchmark.NewEngine()
 			m.logMessages = []string{"UI Refreshed. Select a collection or [ New Benchmark ]."}
+			m.reloadCollections("", "")
 
 			return m, nil
 		}
@@ -139,6 +167,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			statusChangeCmd = m.handleSavingCollectionNameKeys(msg)
 		case StatusSavingEnterTestName:
 			statusChangeCmd = m.handleSavingTestNameKeys(msg)
+		case StatusImportingCurl:
+			statusChangeCmd = m.handleImportingCurlKeys(msg)
+		case StatusViewingExport:
+			statusChangeCmd = m.handleViewingExportKeys(msg)
+		case StatusEnteringName:
+			statusChangeCmd = m.handleEnteringNameKeys(msg)
+		case StatusConfirming:
+			statusChangeCmd = m.handleConfirmingKeys(msg)
+		case StatusSearching:
+			statusChangeCmd = m.handleSearchingKeys(msg)
+		case StatusLoadingBody:
+			statusChangeCmd = m.handleLoadingBodyKeys(msg)
+		case StatusEnteringMethod:
+			statusChangeCmd = m.handleEnteringMethodKeys(msg)
 		}
 
 		if statusChangeCmd != nil {
@@ -151,6 +193,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case progressMsg:
 		if m.progressChan != nil && (m.status == StatusRunning || m.status == StatusStopping) {
 			m.lastProgress = metrics.ProgressUpdate(msg)
+			for _, d := range msg.Decisions {
+				if d.From != d.To {
+					m.addLog(fmt.Sprintf("Concurrency %d -> %d: %s.", d.From, d.To, d.Reason))
+				}
+			}
 		}
 	case resultMsg:
 		m.addLog("Received resultMsg.")
@@ -167,17 +214,29 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.status = StatusCompleted
 				duration := finalResult.TotalDuration.Round(time.Millisecond)
 				m.addLog(successStyle.Render(fmt.Sprintf("Benchmark completed in %s.", duration)))
+				if search := finalResult.Search; search != nil {
+					m.addLog(fmt.Sprintf("Capacity search: %.1f req/s sustainable after %d steps.", search.SustainableRate, len(search.Steps)))
+				}
 			}
 			m.lastProgress = metrics.ProgressUpdate{
 				RequestsAttempted: finalResult.TotalRequestsSent, RequestsCompleted: finalResult.TotalRequestsCompleted,
 				Errors: finalResult.TotalErrors, CurrentThroughput: finalResult.Throughput, CurrentErrorRate: finalResult.ErrorRate,
 				LatencyAvg: finalResult.LatencyAvg, LatencyP95: finalResult.LatencyP95, LatencyP99: finalResult.LatencyP99,
-				LatencyData: finalResult.LatencyData,
+				LatencyData: finalResult.LatencyData, Search: finalResult.Search, Concurrency: finalResult.Concurrency,
+				DroppedArrivals: finalResult.DroppedArrivals, LateArrivals: finalResult.LateArrivals,
+				Series: finalResult.Series,
 			}
 			m.addLog("Nil-ing progressChan and resultChan after resultMsg.")
 			m.progressChan = nil
 			m.resultChan = nil
 		}
+	case clipboardMsg:
+		if msg.err != nil {
+			m.exportNotice = fmt.Sprintf("Copy failed: %v", msg.err)
+		} else {
+			m.exportNotice = fmt.Sprintf("Copied %s to clipboard.", msg.what)
+		}
+		m.addLog(m.exportNotice)
 	case benchmarkCompleteMsg:
 		if m.resultChan != nil && (m.status == StatusRunning || m.status == StatusStopping) {
 			m.addLog("benchmarkCompleteMsg relevant. Setting StatusCompleted.")
@@ -200,13 +259,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		isActionKey := false
 		switch m.status {
 		case StatusIdle:
-			isActionKey = key.Matches(keyMsg, m.keys.Start, m.keys.Back, m.keys.Up, m.keys.Down, m.keys.Save)
-		case StatusSavingEnterCollectionName, StatusSavingEnterTestName:
+			isActionKey = key.Matches(keyMsg, m.keys.Back, m.keys.Save, m.keys.NextField, m.keys.PrevField,
+				m.keys.FormatBody, m.keys.MinifyBody, m.keys.LoadBody, m.keys.BodyType)
+			// Enter and the arrows move within the payload editor.
+			if m.focusedInput != payloadInput {
+				isActionKey = isActionKey || key.Matches(keyMsg, m.keys.Start, m.keys.Up, m.keys.Down)
+			}
+		case StatusSavingEnterCollectionName, StatusSavingEnterTestName, StatusImportingCurl, StatusEnteringName, StatusLoadingBody, StatusEnteringMethod:
 			isActionKey = key.Matches(keyMsg, m.keys.Enter, m.keys.Back)
+		case StatusSearching:
+			isActionKey = key.Matches(keyMsg, m.keys.Enter, m.keys.Back, m.keys.Up, m.keys.Down)
 
 		}
 
-		if !isActionKey {
+		// A key that switched views is not also typed into the new view's input.
+		if !isActionKey && m.status == statusBeforeMsg {
 			switch m.status {
 			case StatusIdle:
 
@@ -215,6 +282,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.saveCollectionNameInput, textInputCmd = m.saveCollectionNameInput.Update(keyMsg)
 			case StatusSavingEnterTestName:
 				m.saveTestNameInput, textInputCmd = m.saveTestNameInput.Update(keyMsg)
+			case StatusImportingCurl:
+				m.curlImportInput, textInputCmd = m.curlImportInput.Update(keyMsg)
+			case StatusEnteringName:
+				m.manageInput, textInputCmd = m.manageInput.Update(keyMsg)
+			case StatusSearching:
+				m.searchInput, textInputCmd = m.searchInput.Update(keyMsg)
+				m.updateSearchResults()
+			case StatusLoadingBody:
+				m.bodyFileInput, textInputCmd = m.bodyFileInput.Update(keyMsg)
+			case StatusEnteringMethod:
+				m.methodInput, textInputCmd = m.methodInput.Update(keyMsg)
 			}
 		}
 	}
@@ -250,36 +328,46 @@ func (m *Model) handleRunningKeys(msg tea.KeyMsg, _ *[]tea.Cmd) tea.Cmd {
 		} else {
 			m.addLog("Stop key pressed but status was not Running.")
 		}
+	case key.Matches(msg, m.keys.Chart):
+		m.vizMode = m.vizMode.next()
 	}
 	return nil
 }
 
 func (m *Model) handleIdleKeys(msg tea.KeyMsg, cmds *[]tea.Cmd) tea.Cmd {
 	var cmd tea.Cmd
-	numInputs := 4
-	currentMethod := ""
+	numInputs := numConfigInputs
 
-	if m.selectedMethod >= 0 && m.selectedMethod < len(m.httpMethods) {
-		currentMethod = m.httpMethods[m.selectedMethod]
-	} else {
+	if m.selectedMethod < 0 || m.selectedMethod >= len(m.httpMethods) {
 		m.addLog(fmt.Sprintf("Warning: Invalid selectedMethod index %d in handleIdleKeys", m.selectedMethod))
-
-		if len(m.httpMethods) > 0 {
-			m.selectedMethod = 0
-			currentMethod = m.httpMethods[0]
-		}
-	}
-
-	if currentMethod == "POST" || currentMethod == "PUT" || currentMethod == "PATCH" {
-		numInputs = 5
+		m.selectedMethod = 0
 	}
+	editingPayload := m.focusedInput == payloadInput
 
 	switch {
+	case editingPayload && key.Matches(msg, m.keys.Start, m.keys.Up, m.keys.Down):
+		// Typed into the editor.
+
+	case key.Matches(msg, m.keys.FormatBody, m.keys.MinifyBody):
+		m.reformatPayload(key.Matches(msg, m.keys.FormatBody))
+
+	case key.Matches(msg, m.keys.LoadBody):
+		return m.startLoadingBody()
+
+	case key.Matches(msg, m.keys.BodyType):
+		m.cycleBodyType()
+
 	case key.Matches(msg, m.keys.Start):
 		m.configError = ""
 		m.saveError = ""
 		m.addLog("Start key pressed in Idle. Parsing config...")
 		cfg, err := m.parseConfig()
+		if err == nil {
+			cfg, err = m.resolveConfig(cfg)
+		}
+		if err == nil {
+			err = cfg.CheckFiles()
+		}
 		if err != nil {
 			m.configError = fmt.Sprintf("Config Error: %v", err)
 			m.addLog(m.configError)
@@ -317,7 +405,7 @@ func (m *Model) handleIdleKeys(msg tea.KeyMsg, cmds *[]tea.Cmd) tea.Cmd {
 			}
 		}
 
-	case key.Matches(msg, m.keys.Down):
+	case key.Matches(msg, m.keys.Down, m.keys.NextField):
 		if m.focusedInput < 0 {
 			m.focusedInput = 0
 		} else {
@@ -326,7 +414,7 @@ func (m *Model) handleIdleKeys(msg tea.KeyMsg, cmds *[]tea.Cmd) tea.Cmd {
 		m.updateInputFocus()
 		cmd = textinput.Blink
 
-	case key.Matches(msg, m.keys.Up):
+	case key.Matches(msg, m.keys.Up, m.keys.PrevField):
 		if m.focusedInput < 0 {
 			m.focusedInput = numInputs - 1
 		} else {
@@ -335,6 +423,22 @@ func (m *Model) handleIdleKeys(msg tea.KeyMsg, cmds *[]tea.Cmd) tea.Cmd {
 		m.updateInputFocus()
 		cmd = textinput.Blink
 
+	case key.Matches(msg, m.keys.Export):
+		cfg, err := m.parseConfig()
+		if err == nil {
+			cfg, err = m.resolveConfig(cfg)
+		}
+		if err != nil {
+			m.configError = fmt.Sprintf("Cannot export: Config Error: %v", err)
+			m.addLog(m.configError)
+			return nil
+		}
+		m.showExport("current configuration", cfg)
+
+	case editingPayload && key.Matches(msg, m.keys.Back):
+		m.focusedInput = -1
+		m.addLog("Left the payload editor. Press Enter to start.")
+
 	case key.Matches(msg, m.keys.Back):
 		m.status = StatusSelectingMethod
 		m.addLog("Back key in Idle: Returning to method selection.")
@@ -391,6 +495,16 @@ func (m *Model) handleSavingTestNameKeys(msg tea.KeyMsg) tea.Cmd {
 
 		configDir := config.GetConfigDir()
 		err := config.SaveTestToCollection(configDir, collectionName, testName, *m.currentConfigToSave)
+		savedCollection, savedTest := config.SavedNames(configDir, collectionName, testName)
+		if errors.Is(err, config.ErrTestExists) {
+			m.startConfirm(manageOp{
+				action:     actionOverwriteTest,
+				collection: savedCollection,
+				test:       savedTest,
+				cfg:        m.currentConfigToSave,
+			})
+			return nil
+		}
 		if err != nil {
 			m.saveError = fmt.Sprintf("Save failed: %v", err)
 			m.addLog(m.saveError)
@@ -399,6 +513,7 @@ func (m *Model) handleSavingTestNameKeys(msg tea.KeyMsg) tea.Cmd {
 		}
 
 		m.addLog(successStyle.Render(fmt.Sprintf("Successfully saved test '%s' to collection '%s'.", testName, collectionName)))
+		m.reloadCollections(savedCollection, savedTest)
 		m.status = StatusIdle
 		m.currentConfigToSave = nil
 		m.saveError = ""
@@ -420,6 +535,9 @@ func (m *Model) handleViewingTestsKeys(msg tea.KeyMsg) tea.Cmd {
 		return nil
 	}
 	currentCollection := m.testCollections[m.selectedCollection]
+	if handled, cmd := m.handleTestManageKeys(msg, currentCollection); handled {
+		return cmd
+	}
 	if len(currentCollection.Tests) == 0 {
 		if key.Matches(msg, m.keys.Back) {
 			m.status = StatusViewingCollections
@@ -439,34 +557,24 @@ func (m *Model) handleViewingTestsKeys(msg tea.KeyMsg) tea.Cmd {
 			m.addLog(fmt.Sprintf("Warning: Invalid selectedTest index %d in handleViewingTestsKeys", m.selectedTest))
 			return nil
 		}
-		selectedTest := currentCollection.Tests[m.selectedTest]
-
-		m.targetURLInput.SetValue(selectedTest.Config.TargetURL)
-		m.threadsInput.SetValue(strconv.Itoa(selectedTest.Config.Threads))
-		m.connectionsInput.SetValue(strconv.Itoa(selectedTest.Config.Connections))
-		m.durationInput.SetValue(selectedTest.Config.Duration)
-		m.requestPayload.SetValue(selectedTest.Config.Payload)
+		return m.loadTest(m.selectedCollection, m.selectedTest)
 
-		m.selectedMethod = 0
-		if selectedTest.Config.Method != "" {
-			found := false
-			for i, method := range m.httpMethods {
-				if strings.EqualFold(method, selectedTest.Config.Method) {
-					m.selectedMethod = i
-					found = true
-					break
-				}
-			}
-			if !found {
-				m.addLog(fmt.Sprintf("Warning: Method '%s' from test '%s' not found. Defaulting to GET.", selectedTest.Config.Method, selectedTest.Name))
+	case key.Matches(msg, m.keys.Export):
+		if m.selectedTest >= 0 && m.selectedTest < len(currentCollection.Tests) {
+			selectedTest := currentCollection.Tests[m.selectedTest]
+			cfg, err := currentCollection.Resolve(selectedTest.Config, m.environment)
+			if err != nil {
+				m.addLog(fmt.Sprintf("Cannot export '%s': %v", selectedTest.Name, err))
+				return nil
 			}
+			m.showExport(selectedTest.Name, cfg)
 		}
 
-		m.status = StatusIdle
-		m.addLog(fmt.Sprintf("Loaded test '%s' from '%s'. Ready to start or modify.", selectedTest.Name, currentCollection.Name))
-		m.configError = ""
-		m.focusedInput = 0
-		return textinput.Blink
+	case key.Matches(msg, m.keys.Env):
+		m.cycleEnvironment()
+
+	case key.Matches(msg, m.keys.Search):
+		return m.startSearch()
 
 	case key.Matches(msg, m.keys.Back):
 		m.status = StatusViewingCollections
@@ -476,6 +584,63 @@ func (m *Model) handleViewingTestsKeys(msg tea.KeyMsg) tea.Cmd {
 	return nil
 }
 
+// loadTest loads a test into the configuration view.
+func (m *Model) loadTest(collectionIndex, testIndex int) tea.Cmd {
+	collection := m.testCollections[collectionIndex]
+	test := collection.Tests[testIndex]
+
+	if !m.loadConfig(test.Config) {
+		m.addLog(fmt.Sprintf("Warning: Method '%s' from test '%s' is invalid. Defaulting to GET.", test.Config.Method, test.Name))
+	}
+	m.activeCollection = collectionIndex
+	m.selectedCollection = collectionIndex
+	m.selectedTest = testIndex
+	if test.Config.Method == "" {
+		m.selectMethod(m.inheritedMethod())
+	}
+
+	m.status = StatusIdle
+	m.addLog(fmt.Sprintf("Loaded test '%s' from '%s'. Ready to start or modify.", test.Name, collection.Name))
+	m.configError = ""
+	m.focusedInput = 0
+	return textinput.Blink
+}
+
+// showExport renders cfg as a curl command and raw HTTP request and switches
+// to the export view, returning to the current view on Esc.
+func (m *Model) showExport(name string, cfg config.BenchmarkConfig) {
+	raw, err := benchmark.RawRequest(cfg)
+	if err != nil {
+		m.configError = fmt.Sprintf("Cannot export: %v", err)
+		m.addLog(m.configError)
+		return
+	}
+	m.exportName = name
+	m.exportCurl = convert.FormatCurl(cfg)
+	m.exportRaw = string(raw)
+	m.exportNotice = ""
+	m.exportReturnStatus = m.status
+	m.status = StatusViewingExport
+	m.addLog(fmt.Sprintf("Exported '%s'. Press c to copy curl, r to copy the raw request.", name))
+}
+
+func (m *Model) handleViewingExportKeys(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.keys.CopyCurl):
+		return copyToClipboard("curl command", m.exportCurl)
+	case key.Matches(msg, m.keys.CopyRaw):
+		return copyToClipboard("raw HTTP request", m.exportRaw)
+	case key.Matches(msg, m.keys.Back):
+		m.status = m.exportReturnStatus
+		m.exportNotice = ""
+		if m.status == StatusIdle {
+			m.focusedInput = 0
+			return textinput.Blink
+		}
+	}
+	return nil
+}
+
 func (m *Model) updateFocusedInput(msg tea.Msg) tea.Cmd {
 	var cmd tea.Cmd
 	if m.focusedInput < 0 {
@@ -500,6 +665,9 @@ func (m *Model) updateFocusedInput(msg tea.Msg) tea.Cmd {
 
 func (m *Model) handleViewingCollectionsKeys(msg tea.KeyMsg) tea.Cmd {
 	totalOptions := len(m.testCollections) + 1
+	if handled, cmd := m.handleCollectionManageKeys(msg); handled {
+		return cmd
+	}
 
 	switch {
 	case key.Matches(msg, m.keys.Down):
@@ -518,17 +686,68 @@ func (m *Model) handleViewingCollectionsKeys(msg tea.KeyMsg) tea.Cmd {
 			m.selectedTest = 0
 			m.addLog(fmt.Sprintf("Viewing tests in collection: %s", m.testCollections[m.selectedCollection].Name))
 		}
+	case key.Matches(msg, m.keys.Env):
+		m.cycleEnvironment()
+	case key.Matches(msg, m.keys.Search):
+		return m.startSearch()
+	case key.Matches(msg, m.keys.Import):
+		m.status = StatusImportingCurl
+		m.importError = ""
+		m.curlImportInput.SetValue("")
+		m.addLog("Paste a curl command to import.")
+		return textinput.Blink
+	}
+	return nil
+}
+
+func (m *Model) handleImportingCurlKeys(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.keys.Enter):
+		cfg, warnings, err := convert.ParseCurl(m.curlImportInput.Value())
+		for _, w := range warnings {
+			m.addLog("Import warning: " + w)
+		}
+		if err != nil {
+			m.importError = fmt.Sprintf("Import failed: %v", err)
+			m.addLog(m.importError)
+			return textinput.Blink
+		}
+		m.importError = ""
+
+		m.clearConfigInputs()
+		if !m.loadConfig(cfg) {
+			m.addLog(fmt.Sprintf("Warning: Method '%s' is invalid. Defaulting to GET.", cfg.Method))
+		}
+		m.currentConfigToSave = &cfg
+		m.saveCollectionNameInput.SetValue("imported")
+		m.saveTestNameInput.SetValue(convert.TestNameFromRequest(cfg.Method, cfg.TargetURL))
+		m.status = StatusSavingEnterCollectionName
+		m.addLog(fmt.Sprintf("Imported %s %s. Choose where to save it.", cfg.Method, cfg.TargetURL))
+		return textinput.Blink
+
+	case key.Matches(msg, m.keys.Back):
+		m.status = StatusViewingCollections
+		m.importError = ""
+		m.addLog("Import cancelled.")
 	}
 	return nil
 }
 
 func (m *Model) handleSelectingMethodKeys(msg tea.KeyMsg) tea.Cmd {
+	totalOptions := len(m.httpMethods) + 1
 	switch {
 	case key.Matches(msg, m.keys.Down):
-		m.selectedMethod = (m.selectedMethod + 1) % len(m.httpMethods)
+		m.selectedMethod = (m.selectedMethod + 1) % totalOptions
 	case key.Matches(msg, m.keys.Up):
-		m.selectedMethod = (m.selectedMethod - 1 + len(m.httpMethods)) % len(m.httpMethods)
+		m.selectedMethod = (m.selectedMethod - 1 + totalOptions) % totalOptions
 	case key.Matches(msg, m.keys.Enter):
+		if m.selectedMethod == len(m.httpMethods) {
+			m.status = StatusEnteringMethod
+			m.methodInput.SetValue("")
+			m.methodError = ""
+			m.addLog("Enter a custom HTTP method.")
+			return textinput.Blink
+		}
 		m.status = StatusIdle
 		m.focusedInput = 0
 		m.addLog(fmt.Sprintf("Selected method: %s. Configure benchmark details.", m.httpMethods[m.selectedMethod]))
@@ -543,6 +762,26 @@ func (m *Model) handleSelectingMethodKeys(msg tea.KeyMsg) tea.Cmd {
 	return nil
 }
 
+func (m *Model) handleEnteringMethodKeys(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.keys.Enter):
+		method := strings.TrimSpace(m.methodInput.Value())
+		if err := config.CheckMethod(method); err != nil {
+			m.methodError = err.Error()
+			return textinput.Blink
+		}
+		m.selectMethod(method)
+		m.status = StatusIdle
+		m.focusedInput = 0
+		m.addLog(fmt.Sprintf("Selected method: %s. Configure benchmark details.", method))
+		return textinput.Blink
+	case key.Matches(msg, m.keys.Back):
+		m.status = StatusSelectingMethod
+		m.methodError = ""
+	}
+	return nil
+}
+
 func (m *Model) handleFinishedKeys(msg tea.KeyMsg, _ *[]tea.Cmd) tea.Cmd {
 	switch {
 	case key.Matches(msg, m.keys.Start):
@@ -553,6 +792,15 @@ func (m *Model) handleFinishedKeys(msg tea.KeyMsg, _ *[]tea.Cmd) tea.Cmd {
 		m.focusedInput = 0
 		m.updateInputFocus()
 		return textinput.Blink
+	case key.Matches(msg, m.keys.Save):
+		path, err := m.saveResult()
+		if err != nil {
+			m.addLog(errorStyle.Render(fmt.Sprintf("Saving result failed: %v", err)))
+			return nil
+		}
+		m.addLog(successStyle.Render(fmt.Sprintf("Result saved to %s.", path)))
+	case key.Matches(msg, m.keys.Chart):
+		m.vizMode = m.vizMode.next()
 	}
 	return nil
 }
@@ -6,7 +6,9 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/Th4phat/go-wrk/config"
 	"github.com/Th4phat/go-wrk/metrics"
 
 	"github.com/charmbracelet/lipgloss"
@@ -37,6 +39,20 @@ func (m Model) View() string {
 		middleView = m.viewSavingCollectionName()
 	case StatusSavingEnterTestName:
 		middleView = m.viewSavingTestName()
+	case StatusImportingCurl:
+		middleView = m.viewImportingCurl()
+	case StatusViewingExport:
+		middleView = m.viewExport()
+	case StatusEnteringName:
+		middleView = m.viewEnteringName()
+	case StatusConfirming:
+		middleView = m.viewConfirming()
+	case StatusSearching:
+		middleView = m.viewSearch()
+	case StatusLoadingBody:
+		middleView = m.viewLoadingBody()
+	case StatusEnteringMethod:
+		middleView = m.viewEnteringMethod()
 	default:
 		middleView = "Unknown application state."
 	}
@@ -107,6 +123,7 @@ func (m Model) View() string {
 }
 
 func (m Model) viewStatus() string {
+	base := m.effectiveConfig()
 	var statusLine string
 	elapsed := time.Since(m.startTime).Round(time.Second)
 
@@ -114,7 +131,11 @@ func (m Model) viewStatus() string {
 	case StatusIdle:
 		statusLine = statusIdleStyle.Render("Status: Idle (Configuring)")
 	case StatusRunning:
-		statusLine = statusRunStyle.Render(fmt.Sprintf("Status: Running (Elapsed: %s)", elapsed))
+		if phase := m.lastProgress.Phase; phase != "" && (base.Warmup != "" || base.Cooldown != "") {
+			statusLine = statusRunStyle.Render(fmt.Sprintf("Status: Running (Elapsed: %s, %s)", elapsed, phase))
+		} else {
+			statusLine = statusRunStyle.Render(fmt.Sprintf("Status: Running (Elapsed: %s)", elapsed))
+		}
 	case StatusStopping:
 		statusLine = statusStopStyle.Render("Status: Stopping...")
 	case StatusCompleted:
@@ -139,6 +160,20 @@ func (m Model) viewStatus() string {
 		statusLine = statusIdleStyle.Render("Status: Saving (Enter Collection Name)")
 	case StatusSavingEnterTestName:
 		statusLine = statusIdleStyle.Render("Status: Saving (Enter Test Name)")
+	case StatusImportingCurl:
+		statusLine = statusIdleStyle.Render("Status: Importing curl Command")
+	case StatusViewingExport:
+		statusLine = statusIdleStyle.Render("Status: Exporting Test")
+	case StatusEnteringName:
+		statusLine = statusIdleStyle.Render("Status: Managing Collections")
+	case StatusConfirming:
+		statusLine = statusStopStyle.Render("Status: Confirm")
+	case StatusSearching:
+		statusLine = statusIdleStyle.Render("Status: Searching Tests")
+	case StatusLoadingBody:
+		statusLine = statusIdleStyle.Render("Status: Loading Payload File")
+	case StatusEnteringMethod:
+		statusLine = statusIdleStyle.Render("Status: Selecting Method")
 	default:
 		statusLine = "Status: Unknown"
 	}
@@ -158,10 +193,19 @@ func (m Model) viewStatus() string {
 		targetLine = "Target: -"
 	}
 
-	return panelStyle.Width(m.windowWidth - 4).Render(lipgloss.JoinVertical(lipgloss.Left, statusLine, targetLine))
+	lines := []string{statusLine, targetLine}
+	if len(m.environments) > 0 {
+		env := m.environment
+		if env == "" {
+			env = "none"
+		}
+		lines = append(lines, fmt.Sprintf("Environment: %s", env))
+	}
+	return panelStyle.Width(m.windowWidth - 4).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
 }
 
 func (m Model) viewConfig() string {
+	base := m.effectiveConfig()
 	b := strings.Builder{}
 	b.WriteString("Benchmark Configuration:\n")
 
@@ -177,26 +221,70 @@ func (m Model) viewConfig() string {
 	b.WriteString(m.connectionsInput.View() + "\n")
 	b.WriteString(m.durationInput.View() + "\n")
 
-	showPayload := false
-	if m.selectedMethod >= 0 && m.selectedMethod < len(m.httpMethods) {
-		currentMethod := m.httpMethods[m.selectedMethod]
-		if currentMethod == "POST" || currentMethod == "PUT" || currentMethod == "PATCH" {
-			showPayload = true
+	if len(base.Headers) > 0 {
+		names := make([]string, 0, len(base.Headers))
+		for name := range base.Headers {
+			names = append(names, name)
 		}
+		sort.Strings(names)
+		b.WriteString(fmt.Sprintf("Headers: %s\n", strings.Join(names, ", ")))
 	}
-
-	if showPayload {
-		b.WriteString("\n" + m.requestPayload.View() + "\n")
-	} else {
-		b.WriteString("\n")
+	if len(base.Mix) > 0 {
+		b.WriteString(fmt.Sprintf("Request mix: %d weighted requests (replaces the single request)\n", len(base.Mix)))
+	}
+	if users := base.Users; users != nil {
+		line := fmt.Sprintf("Virtual users: %d (replaces threads)", users.Count)
+		if users.ThinkTime != nil {
+			line += fmt.Sprintf(", think time %s", users.ThinkTime)
+		}
+		if users.Pacing != "" {
+			line += fmt.Sprintf(", pacing %s", users.Pacing)
+		}
+		b.WriteString(line + "\n")
+	}
+	if arrivals := base.Arrivals; arrivals != nil {
+		line := fmt.Sprintf("Open-model arrivals: %s (replaces threads)", arrivals)
+		if arrivals.MaxInFlight > 0 {
+			line += fmt.Sprintf(", at most %d in flight", arrivals.MaxInFlight)
+		}
+		if strings.EqualFold(arrivals.OnFull, config.OnFullQueue) {
+			line += ", queued when full"
+		}
+		b.WriteString(line + "\n")
+	}
+	if adaptive := base.Adaptive; adaptive != nil {
+		p := adaptive.Params(base.Connections)
+		b.WriteString(fmt.Sprintf("Adaptive concurrency: %s, %d to %d workers (replaces threads)\n", adaptive, p.Min, p.Max))
+	}
+	if search := base.Search; search != nil {
+		line := fmt.Sprintf("Capacity search: from %g req/s", search.StartRate)
+		if search.MaxRate > 0 {
+			line += fmt.Sprintf(" up to %g req/s", search.MaxRate)
+		}
+		b.WriteString(line + fmt.Sprintf(" under %s\n", search.SLO))
+	}
+	if base.Warmup != "" || base.Cooldown != "" {
+		var windows []string
+		if base.Warmup != "" {
+			windows = append(windows, "warm-up "+base.Warmup)
+		}
+		if base.Cooldown != "" {
+			windows = append(windows, "cool-down "+base.Cooldown)
+		}
+		b.WriteString(fmt.Sprintf("Excluded from statistics: %s\n", strings.Join(windows, ", ")))
 	}
+	if base.SkipVerify() {
+		b.WriteString("TLS certificate verification: disabled\n")
+	}
+
+	b.WriteString("\n" + m.viewPayload())
 
 	if m.configError != "" {
 		b.WriteString(errorStyle.Render(m.configError) + "\n")
 	} else if m.saveError != "" {
 		b.WriteString(errorStyle.Render(m.saveError) + "\n")
 	} else {
-		b.WriteString("Press Enter to Start, Ctrl+S to Save, Esc to change method\n")
+		b.WriteString("Press Enter to Start, Ctrl+S to Save, Ctrl+E to Export, Esc to change method\n")
 	}
 	return panelStyle.Width(m.windowWidth - 4).Render(b.String())
 }
@@ -221,11 +309,52 @@ func (m Model) viewSavingTestName() string {
 	if m.saveError != "" {
 		b.WriteString(errorStyle.Render(m.saveError) + "\n")
 	}
-	b.WriteString("Enter test name (will overwrite if exists).\n")
+	b.WriteString("Enter test name (you will be asked before overwriting an existing test).\n")
 	b.WriteString("Press Enter to save, Esc to go back to collection name.")
 	return panelStyle.Width(m.windowWidth - 4).Render(b.String())
 }
 
+func (m Model) viewImportingCurl() string {
+	b := strings.Builder{}
+	b.WriteString("Import Test from curl\n\n")
+	b.WriteString(m.curlImportInput.View() + "\n\n")
+	if m.importError != "" {
+		b.WriteString(errorStyle.Render(m.importError) + "\n")
+	}
+	b.WriteString("Paste a command copied with \"Copy as cURL\". Unsupported flags are reported in the log.\n")
+	b.WriteString("Press Enter to import, Esc to cancel.")
+	return panelStyle.Width(m.windowWidth - 4).Render(b.String())
+}
+
+func (m Model) viewExport() string {
+	b := strings.Builder{}
+	b.WriteString(fmt.Sprintf("Export of '%s'\n\n", m.exportName))
+	b.WriteString(metricKeyStyle.Render("curl:") + "\n")
+	b.WriteString(m.exportCurl + "\n\n")
+	b.WriteString(metricKeyStyle.Render("Raw HTTP/1.1 request:") + "\n")
+	b.WriteString(printableRequest(m.exportRaw) + "\n\n")
+	if m.exportNotice != "" {
+		b.WriteString(successStyle.Render(m.exportNotice) + "\n")
+	}
+	b.WriteString("Press c to copy curl, r to copy the raw request, Esc to go back.")
+	return panelStyle.Width(m.windowWidth - 4).Render(b.String())
+}
+
+// printableRequest makes a raw request safe to draw: CRLFs become newlines
+// and other control bytes are escaped.
+func printableRequest(raw string) string {
+	raw = strings.ReplaceAll(raw, "\r\n", "\n")
+	var b strings.Builder
+	for _, r := range raw {
+		if r == '\n' || r == '\t' || (r >= 0x20 && r != 0x7f && r != utf8.RuneError) {
+			b.WriteRune(r)
+		} else {
+			b.WriteString(fmt.Sprintf("\\x%02x", r))
+		}
+	}
+	return b.String()
+}
+
 func (m Model) viewCollectionsList() string {
 	b := strings.Builder{}
 	b.WriteString("Select Collection or Start New Benchmark:\n\n")
@@ -246,8 +375,9 @@ func (m Model) viewCollectionsList() string {
 		b.WriteString("  " + newBenchmarkLine + "\n")
 	}
 
-	b.WriteString("\nUse ↑↓ to navigate, Enter to select.")
-	contentHeight := len(m.testCollections) + 1 + 5
+	b.WriteString("\nUse ↑↓ to navigate, Enter to select, / to search, i to import a curl command, e to switch environment.\n")
+	b.WriteString("n: new collection, R: rename, d: delete.")
+	contentHeight := len(m.testCollections) + 1 + 6
 	return panelStyle.Width(m.windowWidth - 4).Height(contentHeight).MaxHeight(m.windowHeight / 3).Render(b.String())
 }
 
@@ -272,8 +402,9 @@ func (m Model) viewTestsList() string {
 		}
 	}
 
-	b.WriteString("\nUse ↑↓ to navigate, Enter to load, Esc to go back.")
-	contentHeight := len(currentCollection.Tests) + 5
+	b.WriteString("\nUse ↑↓ to navigate, Enter to load, / to search, Ctrl+E to export, e to switch environment, Esc to go back.\n")
+	b.WriteString("R: rename, Ctrl+D: duplicate, m: move, d: delete.")
+	contentHeight := len(currentCollection.Tests) + 6
 	return panelStyle.Width(m.windowWidth - 4).Height(contentHeight).MaxHeight(m.windowHeight / 3).Render(b.String())
 }
 
@@ -289,12 +420,32 @@ func (m Model) viewMethodSelection() string {
 		}
 	}
 
+	customLine := "[ Custom method ]"
+	if m.selectedMethod == len(m.httpMethods) {
+		b.WriteString(selectedItemStyle.Render("> "+customLine) + "\n")
+	} else {
+		b.WriteString("  " + customLine + "\n")
+	}
+
 	b.WriteString("\nUse ↑↓ to navigate, Enter to confirm, Esc to go back.")
-	contentHeight := len(m.httpMethods) + 5
-	return panelStyle.Width(m.windowWidth - 4).Height(contentHeight).MaxHeight(m.windowHeight / 3).Render(b.String())
+	contentHeight := len(m.httpMethods) + 6
+	return panelStyle.Width(m.windowWidth - 4).Height(contentHeight).MaxHeight(m.windowHeight / 2).Render(b.String())
+}
+
+func (m Model) viewEnteringMethod() string {
+	b := strings.Builder{}
+	b.WriteString("Custom HTTP Method\n\n")
+	b.WriteString(m.methodInput.View() + "\n\n")
+	if m.methodError != "" {
+		b.WriteString(errorStyle.Render(m.methodError) + "\n")
+	}
+	b.WriteString("Any method name is sent as typed, e.g. PURGE or MKCOL. CONNECT is not supported.\n")
+	b.WriteString("Press Enter to confirm, Esc to go back.")
+	return panelStyle.Width(m.windowWidth - 4).Render(b.String())
 }
 
 func (m Model) viewMetrics() string {
+	base := m.effectiveConfig()
 	b := strings.Builder{}
 	title := "Live Metrics"
 	data := m.lastProgress
@@ -311,9 +462,16 @@ func (m Model) viewMetrics() string {
 				LatencyAvg:        m.finalResult.LatencyAvg,
 				LatencyP95:        m.finalResult.LatencyP95,
 				LatencyP99:        m.finalResult.LatencyP99,
+				DroppedArrivals:   m.finalResult.DroppedArrivals,
+				LateArrivals:      m.finalResult.LateArrivals,
+				Concurrency:       m.finalResult.Concurrency,
+				Series:            m.finalResult.Series,
 			}
 		}
 	}
+	if (m.status == StatusRunning || m.status == StatusStopping) && data.Phase != "" && data.Phase != metrics.PhaseMeasure {
+		title += fmt.Sprintf(" (%s, excluded from statistics)", data.Phase)
+	}
 	b.WriteString(title + ":\n")
 
 	metricsLines := []string{
@@ -326,6 +484,34 @@ func (m Model) viewMetrics() string {
 		fmt.Sprintf("%s %s", metricKeyStyle.Render("Latency P95:"), metricValStyle.Render(data.LatencyP95.Round(time.Millisecond).String())),
 		fmt.Sprintf("%s %s", metricKeyStyle.Render("Latency P99:"), metricValStyle.Render(data.LatencyP99.Round(time.Millisecond).String())),
 	}
+	if users := base.Users; users != nil && (m.status == StatusRunning || m.status == StatusStopping) {
+		metricsLines = append(metricsLines, fmt.Sprintf("%s %s", metricKeyStyle.Render("Active Users:"),
+			metricValStyle.Render(fmt.Sprintf("%d / %d", data.ActiveUsers, users.Count))))
+	}
+	if adaptive := base.Adaptive; adaptive != nil {
+		metricsLines = append(metricsLines, fmt.Sprintf("%s %s", metricKeyStyle.Render("Concurrency:"),
+			metricValStyle.Render(fmt.Sprintf("%d (max %d, %s)", data.Concurrency, adaptive.Params(base.Connections).Max, adaptive))))
+	}
+	if search := m.searchState(); search != nil {
+		metricsLines = append(metricsLines, m.searchMetricsLines(search)...)
+	} else if base.Arrivals != nil {
+		metricsLines = append(metricsLines,
+			fmt.Sprintf("%s %s", metricKeyStyle.Render("Dropped Arrivals:"), metricValStyle.Render(strconv.Itoa(data.DroppedArrivals))),
+			fmt.Sprintf("%s %s", metricKeyStyle.Render("Late Arrivals:"), metricValStyle.Render(strconv.Itoa(data.LateArrivals))))
+	}
+
+	metricsLines = append(metricsLines, seriesLines(data.Series, m.windowWidth-40)...)
+
+	if (m.status == StatusCompleted || m.status == StatusError) && m.finalResult != nil {
+		for _, w := range []struct {
+			name  string
+			stats *metrics.PhaseStats
+		}{{"Warm-up:", m.finalResult.Warmup}, {"Cool-down:", m.finalResult.Cooldown}} {
+			if w.stats != nil {
+				metricsLines = append(metricsLines, fmt.Sprintf("%s %s", metricKeyStyle.Render(w.name), metricValStyle.Render(phaseSummary(w.stats))))
+			}
+		}
+	}
 
 	if (m.status == StatusCompleted || m.status == StatusError) && m.finalResult != nil && len(m.finalResult.ErrorDetails) > 0 {
 		b.WriteString("\nError Summary:\n")
@@ -342,11 +528,23 @@ func (m Model) viewMetrics() string {
 
 	metricsStr := strings.Join(metricsLines, "\n")
 	b.WriteString(metricsStr)
+	if (m.status == StatusCompleted || m.status == StatusError) && m.finalResult != nil {
+		b.WriteString("\n\nPress Enter for a new benchmark, Ctrl+S to save the result with its series.")
+	}
 
 	return panelStyle.Width(m.windowWidth - 4).Render(b.String())
 }
 
+// phaseSummary describes the samples of a warm-up or cool-down window on one
+// line.
+func phaseSummary(s *metrics.PhaseStats) string {
+	return fmt.Sprintf("%d requests, %d errors in %s, %.2f req/sec, p50 %s, p95 %s, p99 %s",
+		s.RequestsCompleted, s.Errors, s.Duration.Round(time.Millisecond), s.Throughput,
+		s.LatencyP50.Round(time.Millisecond), s.LatencyP95.Round(time.Millisecond), s.LatencyP99.Round(time.Millisecond))
+}
+
 func (m Model) viewVisualization() string {
+	base := m.effectiveConfig()
 	if m.status != StatusRunning && m.status != StatusStopping && m.status != StatusCompleted && m.status != StatusError {
 		return ""
 	}
@@ -356,6 +554,25 @@ func (m Model) viewVisualization() string {
 		histWidth = 20
 	}
 
+	series := m.lastProgress.Series
+	if (m.status == StatusCompleted || m.status == StatusError) && m.finalResult != nil {
+		series = m.finalResult.Series
+	}
+	switch m.vizMode {
+	case vizTimeSeries:
+		return "Per-Second Series (v for next chart):\n" + renderTimeSeries(series, histWidth, 6)
+	case vizHeatmap:
+		return "Latency Heatmap (v for next chart):\n" + renderHeatmap(series, histWidth, 6)
+	}
+
+	if search := m.searchState(); search != nil {
+		var sloP99 time.Duration
+		if base.Search != nil {
+			sloP99, _ = time.ParseDuration(base.Search.SLO.LatencyP99)
+		}
+		return "Capacity Search: P99 Latency vs Throughput (● pass, ✕ fail; v for next chart):\n" + renderSearchCurve(search, sloP99, histWidth, 8)
+	}
+
 	dataForHist := m.lastProgress.LatencyData
 	if (m.status == StatusCompleted || m.status == StatusError) && m.finalResult != nil && len(m.finalResult.LatencyData) > 0 {
 		dataForHist = m.finalResult.LatencyData
@@ -366,7 +583,7 @@ func (m Model) viewVisualization() string {
 	}
 
 	hist := renderHistogram(dataForHist, histWidth, 8)
-	return "Latency Distribution (ms):\n" + hist
+	return "Latency Distribution (ms; v for next chart):\n" + hist
 }
 
 func (m Model) viewLogs() string {